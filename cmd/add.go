@@ -128,11 +128,20 @@ func runAdd(ctx context.Context, utils command.Utils) error {
 		TFVarsPath: localPath,
 	}
 
+	fmt.Print("Enter terraform working directory (relative to .tfvarenv.json) [.]: ")
+	workingDir, _ := reader.ReadString('\n')
+	workingDir = filepath.Clean(strings.TrimSpace(workingDir))
+	if workingDir != "." {
+		env.WorkingDir = workingDir
+	}
+
 	// Deployment Configuration
 	fmt.Println("\nDeployment Configuration:")
 	env.Deployment = config.DeploymentConfig{
-		AutoBackup:      prompt.PromptYesNo("Enable auto backup?", true),
-		RequireApproval: prompt.PromptYesNo("Require deployment approval?", envName != "dev"),
+		AutoBackup:            prompt.PromptYesNo("Enable auto backup?", true),
+		RequireApproval:       prompt.PromptYesNo("Require deployment approval?", envName != "dev"),
+		Protected:             prompt.PromptYesNo("Protect environment (destroy is refused and destructive commands require the environment name)?", envName == "prod" || envName == "production"),
+		RequireRemoteApproval: prompt.PromptYesNo("Require approval by another identity before apply?", false),
 	}
 
 	// Setup local environment
@@ -297,6 +306,13 @@ func handleRemoteOnly(ctx context.Context, utils command.Utils, env *config.Envi
 			return fmt.Errorf("failed to write tfvars file: %w", err)
 		}
 
+		if output.VersionID != "" {
+			hash := file.HashContent(output.Content)
+			if err := utils.GetStateManager().SetParentVersion(env.Name, output.VersionID, hash); err != nil {
+				fmt.Printf("Warning: Failed to update local state: %v\n", err)
+			}
+		}
+
 		fmt.Printf("Successfully downloaded tfvars file to %s\n", env.Local.TFVarsPath)
 	} else {
 		fmt.Printf("Action needed: Use 'tfvarenv download %s' when ready to sync\n", env.Name)
@@ -352,6 +368,10 @@ func handleLocalOnly(ctx context.Context, utils command.Utils, env *config.Envir
 			fmt.Println("The file was uploaded successfully, but version tracking may be incomplete.")
 		}
 
+		if err := utils.GetStateManager().SetParentVersion(env.Name, newVersion.VersionID, newVersion.Hash); err != nil {
+			fmt.Printf("Warning: Failed to update local state: %v\n", err)
+		}
+
 		fmt.Println("Successfully uploaded tfvars file.")
 	} else {
 		fmt.Println("Action needed: Use 'tfvarenv upload' when ready to sync.")
@@ -22,18 +22,31 @@ func NewApplyCmd() *cobra.Command {
 	applyCmd := &cobra.Command{
 		Use:   "apply [environment]",
 		Short: "Run terraform apply for the specified environment",
-		Args:  cobra.ExactArgs(1),
+		Args:  cobra.MaximumNArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
-			opts.Environment, err = utils.GetEnvironment(args[0])
+			envName, err := utils.ResolveEnvironmentName(args)
 			if err != nil {
 				fmt.Printf("Error: %v\n", err)
 				os.Exit(1)
 			}
 
+			opts.Environment, err = utils.GetEnvironment(envName)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+			opts.VarFile = utils.ResolvePath(opts.VarFile)
+			if err := requireExplicitEnvironment(cmd, opts.Environment, args); err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+
 			manager := apply.NewManager(
 				utils.GetAWSClient(),
 				utils.GetFileUtils(),
 				utils.GetTerraformRunner(),
+				utils.GetIdentityResolver(),
+				utils.GetEnvironment,
 			)
 
 			if err := manager.Execute(cmd.Context(), &opts); err != nil {
@@ -48,6 +61,13 @@ func NewApplyCmd() *cobra.Command {
 	applyCmd.Flags().StringVar(&opts.VersionID, "version-id", "", "Specific version ID to use (only with --remote)")
 	applyCmd.Flags().StringSliceVar(&opts.TerraformOpts, "options", nil, "Additional options for terraform apply")
 	applyCmd.Flags().BoolVar(&opts.AutoApprove, "auto-approve", false, "Skip interactive approval of plan")
+	applyCmd.Flags().BoolVar(&opts.RequestApproval, "request-approval", false, "Plan and store an approval request instead of applying")
+	applyCmd.Flags().StringVar(&opts.ApprovalID, "approval-id", "", "Apply the version of an approved request")
+	applyCmd.Flags().BoolVar(&opts.Explain, "explain", false, "Show the result of every deployment policy rule")
+	applyCmd.Flags().StringVar(&opts.BreakGlass, "break-glass", "", "Apply inside a freeze window, giving the reason")
+	applyCmd.Flags().BoolVar(&opts.Force, "force", false, "Override plan rules such as the maximum number of changes")
+	applyCmd.Flags().StringSliceVar(&opts.ConfirmDestroy, "confirm-destroy", nil, "Addresses of deleted or replaced resources to confirm without prompting")
+	applyCmd.Flags().BoolVar(&opts.Reinit, "reinit", false, "Re-initialize the backend if it does not match the environment")
 
 	return applyCmd
 }
@@ -0,0 +1,176 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"tfvarenv/config"
+	"tfvarenv/utils/approval"
+	"tfvarenv/utils/command"
+	"tfvarenv/utils/prompt"
+	"tfvarenv/utils/version"
+)
+
+// approveOptions represents approve command options
+type approveOptions struct {
+	List    bool
+	Reject  bool
+	Comment string
+	Yes     bool
+}
+
+func NewApproveCmd() *cobra.Command {
+	utils, err := command.NewUtils()
+	if err != nil {
+		fmt.Printf("Error initializing command utils: %v\n", err)
+		os.Exit(1)
+	}
+
+	var opts approveOptions
+
+	approveCmd := &cobra.Command{
+		Use:   "approve [environment] [request-id]",
+		Short: "Approve or reject an apply request",
+		Long: `Approve or reject a request created with 'tfvarenv apply --request-approval'.
+The request must be reviewed by a different AWS identity (STS caller ARN) than the requester.
+Example: tfvarenv approve prod 3f9a1c2b7d4e
+         tfvarenv approve prod 3f9a1c2b7d4e --reject -m "wrong instance type"
+         tfvarenv approve prod --list`,
+		Args: cobra.RangeArgs(0, 2),
+		Run: func(cmd *cobra.Command, args []string) {
+			if opts.List {
+				if len(args) > 1 {
+					fmt.Printf("Error: --list takes only the environment\n")
+					os.Exit(1)
+				}
+				envName, err := utils.ResolveEnvironmentName(args)
+				if err != nil {
+					fmt.Printf("Error: %v\n", err)
+					os.Exit(1)
+				}
+				if err := runApproveList(cmd.Context(), utils, envName); err != nil {
+					fmt.Printf("Error: %v\n", err)
+					os.Exit(1)
+				}
+				return
+			}
+
+			if len(args) == 0 {
+				fmt.Printf("Error: request ID is required\n")
+				os.Exit(1)
+			}
+			envName, rest, err := splitEnvironmentArgs(utils, args, 1)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+
+			if err := runApprove(cmd.Context(), utils, envName, rest[0], &opts); err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+		},
+	}
+
+	approveCmd.Flags().BoolVar(&opts.List, "list", false, "List approval requests")
+	approveCmd.Flags().BoolVar(&opts.Reject, "reject", false, "Reject the request")
+	approveCmd.Flags().StringVarP(&opts.Comment, "comment", "m", "", "Review comment")
+	approveCmd.Flags().BoolVarP(&opts.Yes, "yes", "y", false, "Skip confirmation")
+
+	return approveCmd
+}
+
+func runApprove(ctx context.Context, utils command.Utils, envName, id string, opts *approveOptions) error {
+	env, err := utils.GetEnvironment(envName)
+	if err != nil {
+		return fmt.Errorf("environment not found: %w", err)
+	}
+
+	approvalManager := approval.NewManager(utils.GetAWSClient(), env)
+	req, err := approvalManager.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	displayApprovalRequest(ctx, utils, env, req)
+
+	action := "approve"
+	if opts.Reject {
+		action = "reject"
+	}
+	if !opts.Yes && !prompt.PromptYesNo(fmt.Sprintf("\nDo you want to %s this request?", action), false) {
+		return fmt.Errorf("review cancelled by user")
+	}
+
+	req, err = approvalManager.Review(ctx, id, !opts.Reject, opts.Comment)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("\nRequest %s %s by %s\n", req.ID, req.Status, req.ReviewedBy)
+	if req.Status == approval.StatusApproved {
+		fmt.Printf("Apply with: tfvarenv apply %s --approval-id %s\n", env.Name, req.ID)
+	}
+	return nil
+}
+
+func runApproveList(ctx context.Context, utils command.Utils, envName string) error {
+	env, err := utils.GetEnvironment(envName)
+	if err != nil {
+		return fmt.Errorf("environment not found: %w", err)
+	}
+
+	requests, err := approval.NewManager(utils.GetAWSClient(), env).List(ctx)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Approval requests for environment '%s':\n", env.Name)
+	if len(requests) == 0 {
+		fmt.Println("No approval requests found")
+		return nil
+	}
+
+	now := time.Now()
+	for _, req := range requests {
+		status := req.Status
+		if (status == approval.StatusPending || status == approval.StatusApproved) && req.IsExpired(now) {
+			status += " (expired)"
+		}
+		summary := ""
+		if req.PlanSummary != nil {
+			summary = req.PlanSummary.String()
+		}
+		fmt.Printf("  %s  %s  %-20s version %s  %s  by %s\n",
+			req.ID,
+			req.RequestedAt.Format("2006-01-02 15:04:05"),
+			status,
+			version.ShortID(req.VersionID),
+			summary,
+			req.RequestedBy)
+	}
+	return nil
+}
+
+func displayApprovalRequest(ctx context.Context, utils command.Utils, env *config.Environment, req *approval.Request) {
+	fmt.Printf("Approval request %s for environment '%s':\n", req.ID, env.Name)
+	fmt.Printf("  Version: %s\n", version.ShortID(req.VersionID))
+	versionManager := version.NewManager(utils.GetAWSClient(), utils.GetFileUtils(), env)
+	if ver, err := versionManager.GetVersion(ctx, req.VersionID); err == nil {
+		fmt.Printf("  Uploaded: %s by %s\n", ver.Timestamp.Format("2006-01-02 15:04:05"), ver.UploadedBy)
+		if ver.Description != "" {
+			fmt.Printf("  Description: %s\n", ver.Description)
+		}
+	}
+	if req.PlanSummary != nil {
+		fmt.Printf("  Plan: %s\n", req.PlanSummary)
+	}
+	fmt.Printf("  Requested By: %s\n", req.RequestedBy)
+	fmt.Printf("  Requested At: %s\n", req.RequestedAt.Format("2006-01-02 15:04:05"))
+	fmt.Printf("  Expires: %s\n", req.ExpiresAt.Format("2006-01-02 15:04:05"))
+	fmt.Printf("  Status: %s\n", req.Status)
+}
@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"tfvarenv/utils/command"
+	"tfvarenv/utils/version"
+)
+
+func NewBlameCmd() *cobra.Command {
+	utils, err := command.NewUtils()
+	if err != nil {
+		fmt.Printf("Error initializing command utils: %v\n", err)
+		os.Exit(1)
+	}
+
+	return &cobra.Command{
+		Use:   "blame [environment]",
+		Short: "Show who last changed each variable",
+		Long: `Annotate each variable of the latest version with the version, uploader and
+timestamp of its last change, based on the versions in the version index.`,
+		Args: cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			envName, err := utils.ResolveEnvironmentName(args)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+
+			if err := runBlame(cmd.Context(), utils, envName); err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+		},
+	}
+}
+
+func runBlame(ctx context.Context, utils command.Utils, envName string) error {
+	env, err := utils.GetEnvironment(envName)
+	if err != nil {
+		return fmt.Errorf("environment not found: %w", err)
+	}
+
+	versionManager := version.NewManager(utils.GetAWSClient(), utils.GetFileUtils(), env)
+	history, err := versionManager.GetVariableHistory(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get variable history: %w", err)
+	}
+
+	fmt.Printf("Variable blame for environment '%s':\n\n", env.Name)
+	if len(history.Names) == 0 {
+		fmt.Println("No variables found")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tUPLOADED\tBY\tVARIABLE")
+	for _, name := range history.Names {
+		changes := history.Changes[name]
+		last := changes[len(changes)-1]
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s = %s\n",
+			version.ShortID(last.Version.VersionID),
+			last.Version.Timestamp.Format("2006-01-02 15:04:05"),
+			last.Version.UploadedBy,
+			name,
+			firstLine(last.Value))
+	}
+	w.Flush()
+
+	printSkippedVersions(history)
+	return nil
+}
+
+func printSkippedVersions(history *version.VariableHistory) {
+	if len(history.SkippedVersions) == 0 {
+		return
+	}
+	fmt.Printf("\nWarning: %d version(s) could not be read and were skipped:\n", len(history.SkippedVersions))
+	for _, id := range history.SkippedVersions {
+		fmt.Printf("  %s\n", version.ShortID(id))
+	}
+}
+
+// firstLine returns the first line of a multi-line value
+func firstLine(value string) string {
+	for i, r := range value {
+		if r == '\n' {
+			return value[:i] + " ..."
+		}
+	}
+	return value
+}
@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"tfvarenv/utils/changelog"
+	"tfvarenv/utils/command"
+	"tfvarenv/utils/deployment"
+	"tfvarenv/utils/version"
+)
+
+func NewChangelogCmd() *cobra.Command {
+	utils, err := command.NewUtils()
+	if err != nil {
+		fmt.Printf("Error initializing command utils: %v\n", err)
+		os.Exit(1)
+	}
+
+	var from, to, format string
+
+	changelogCmd := &cobra.Command{
+		Use:   "changelog [environment]",
+		Short: "Show the changes between two deployments",
+		Long: `Show the versions uploaded and the net variable changes between two deployments.
+--from and --to accept a deployment ID, a deployed version ID (prefix), "latest", or a timestamp
+(the deployment active at that time). By default the latest deployment is compared
+with the one before it.
+
+Example: tfvarenv changelog prod
+         tfvarenv changelog prod --from 2024-05-01 --to latest --format json`,
+		Args: cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			envName, err := utils.ResolveEnvironmentName(args)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+
+			if err := runChangelog(cmd.Context(), utils, envName, from, to, format); err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+		},
+	}
+
+	changelogCmd.Flags().StringVar(&from, "from", "", "Start deployment (deployment ID, version ID, \"latest\" or timestamp)")
+	changelogCmd.Flags().StringVar(&to, "to", "", "End deployment (deployment ID, version ID, \"latest\" or timestamp; default: latest)")
+	changelogCmd.Flags().StringVar(&format, "format", changelog.FormatMarkdown, "Output format (markdown, json)")
+
+	return changelogCmd
+}
+
+func runChangelog(ctx context.Context, utils command.Utils, envName, from, to, format string) error {
+	if format != changelog.FormatMarkdown && format != changelog.FormatJSON {
+		return fmt.Errorf("unsupported format: %s (must be %s or %s)", format, changelog.FormatMarkdown, changelog.FormatJSON)
+	}
+
+	env, err := utils.GetEnvironment(envName)
+	if err != nil {
+		return fmt.Errorf("environment not found: %w", err)
+	}
+
+	versionManager := version.NewManager(utils.GetAWSClient(), utils.GetFileUtils(), env)
+	deploymentManager := deployment.NewManager(utils.GetAWSClient(), env)
+	changelogManager := changelog.NewManager(env.Name, versionManager, deploymentManager)
+
+	result, err := changelogManager.Generate(ctx, parseChangelogRef(from), parseChangelogRef(to))
+	if err != nil {
+		return fmt.Errorf("failed to generate changelog: %w", err)
+	}
+
+	return changelog.Render(os.Stdout, result, format)
+}
+
+// parseChangelogRef interprets a flag value as a timestamp if possible, otherwise as a deployment
+func parseChangelogRef(value string) changelog.Ref {
+	if value == "" {
+		return changelog.Ref{}
+	}
+	if t, err := parseTimestamp(value); err == nil {
+		return changelog.Ref{At: t}
+	}
+	return changelog.Ref{Deployment: value}
+}
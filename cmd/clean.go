@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/spf13/cobra"
+
+	"tfvarenv/utils/command"
+	"tfvarenv/utils/prompt"
+	"tfvarenv/utils/terraform"
+)
+
+// cleanOptions represents clean command options
+type cleanOptions struct {
+	All    bool
+	DryRun bool
+	Force  bool
+}
+
+func NewCleanCmd() *cobra.Command {
+	utils, err := command.NewUtils()
+	if err != nil {
+		fmt.Printf("Error initializing command utils: %v\n", err)
+		os.Exit(1)
+	}
+
+	var opts cleanOptions
+
+	cleanCmd := &cobra.Command{
+		Use:   "clean [environment...]",
+		Short: "Remove Terraform data directories",
+		Long: `Remove the Terraform data directories of environments.
+Without arguments, the data directories of environments that no longer exist in
+.tfvarenv.json are removed. The data directory of an environment is re-initialized
+on the next plan or apply.
+Example: tfvarenv clean
+         tfvarenv clean dev --force`,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := runClean(utils, args, &opts); err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+		},
+	}
+
+	cleanCmd.Flags().BoolVar(&opts.All, "all", false, "Remove the data directories of all environments")
+	cleanCmd.Flags().BoolVar(&opts.DryRun, "dry-run", false, "Only show what would be removed")
+	cleanCmd.Flags().BoolVarP(&opts.Force, "force", "f", false, "Remove without confirmation")
+
+	return cleanCmd
+}
+
+func runClean(utils command.Utils, envNames []string, opts *cleanOptions) error {
+	entries, err := os.ReadDir(terraform.DataDirRoot)
+	if err != nil {
+		if os.IsNotExist(err) {
+			fmt.Println("No Terraform data directories found.")
+			return nil
+		}
+		return fmt.Errorf("failed to read %s: %w", terraform.DataDirRoot, err)
+	}
+
+	configured := make(map[string]bool)
+	if names, err := utils.ListEnvironments(); err == nil {
+		for _, name := range names {
+			configured[name] = true
+		}
+	}
+	requested := make(map[string]bool)
+	for _, name := range envNames {
+		requested[name] = true
+	}
+
+	var targets []string
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		name := entry.Name()
+		switch {
+		case opts.All, requested[name]:
+			targets = append(targets, name)
+		case len(envNames) == 0 && !configured[name]:
+			// 設定から削除された環境のディレクトリ
+			targets = append(targets, name)
+		}
+	}
+	sort.Strings(targets)
+
+	if len(targets) == 0 {
+		fmt.Println("Nothing to clean.")
+		return nil
+	}
+
+	fmt.Println("Terraform data directories to remove:")
+	for _, name := range targets {
+		status := ""
+		if !configured[name] {
+			status = " (environment no longer exists)"
+		}
+		fmt.Printf("  %s%s\n", filepath.Join(terraform.DataDirRoot, name), status)
+	}
+
+	if opts.DryRun {
+		return nil
+	}
+	if !opts.Force && !prompt.PromptYesNo("\nRemove these directories?", false) {
+		return fmt.Errorf("clean cancelled by user")
+	}
+
+	for _, name := range targets {
+		if err := os.RemoveAll(filepath.Join(terraform.DataDirRoot, name)); err != nil {
+			return fmt.Errorf("failed to remove data directory of %s: %w", name, err)
+		}
+	}
+
+	fmt.Printf("\nRemoved %d data directories\n", len(targets))
+	return nil
+}
@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/template"
+
+	"github.com/spf13/cobra"
+
+	"tfvarenv/config"
+	"tfvarenv/utils/command"
+)
+
+// currentInfo is the data available to the current command's --format template
+type currentInfo struct {
+	Name        string
+	Source      string
+	Description string
+	AccountID   string
+	Region      string
+	Protected   bool
+	DataDir     string
+}
+
+func NewCurrentCmd() *cobra.Command {
+	utils, err := command.NewUtils()
+	if err != nil {
+		fmt.Printf("Error initializing command utils: %v\n", err)
+		os.Exit(1)
+	}
+
+	var format string
+
+	currentCmd := &cobra.Command{
+		Use:   "current",
+		Short: "Print the current environment",
+		Long: `Print the environment used when the environment argument is omitted.
+It is set with 'tfvarenv use' and can be overridden with the ` + command.EnvironmentVariable + ` environment variable.
+Nothing is printed and the exit status is 1 when no environment is selected,
+which makes the command suitable for shell prompts.
+
+Available fields for --format: .Name, .Source, .Description, .AccountID, .Region, .Protected, .DataDir
+Example: tfvarenv current
+         tfvarenv current --format '({{.Name}}{{if .Protected}}!{{end}})'`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := runCurrent(utils, format); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+		},
+	}
+
+	currentCmd.Flags().StringVar(&format, "format", "{{.Name}}", "Go template for the output")
+
+	return currentCmd
+}
+
+func runCurrent(utils command.Utils, format string) error {
+	tmpl, err := template.New("current").Parse(format)
+	if err != nil {
+		return fmt.Errorf("invalid format: %w", err)
+	}
+
+	current, err := utils.GetCurrentEnvironment()
+	if err != nil {
+		return err
+	}
+	if current == nil {
+		os.Exit(1)
+	}
+
+	info := currentInfo{
+		Name:   current.Name,
+		Source: current.Source,
+	}
+	if env, err := utils.GetEnvironment(current.Name); err == nil {
+		info.Description = env.Description
+		info.AccountID = env.AWS.AccountID
+		info.Region = env.AWS.Region
+		info.Protected = env.Deployment.Protected
+		if dataDir, err := filepath.Abs(utils.GetTerraformRunner().DataDir(env)); err == nil {
+			info.DataDir = dataDir
+		}
+	}
+
+	if err := tmpl.Execute(os.Stdout, info); err != nil {
+		return fmt.Errorf("failed to render format: %w", err)
+	}
+	fmt.Println()
+	return nil
+}
+
+// requireExplicitEnvironment refuses to run a destructive command against a protected
+// environment unless the environment was named on the command line
+func requireExplicitEnvironment(cmd *cobra.Command, env *config.Environment, args []string) error {
+	if len(args) > 0 || !env.Deployment.Protected {
+		return nil
+	}
+	return fmt.Errorf("environment '%s' is protected; specify it explicitly: %s %s",
+		env.Name, cmd.CommandPath(), env.Name)
+}
+
+// splitEnvironmentArgs splits the arguments into the environment and the remaining n arguments.
+// The environment may be omitted to use the current environment.
+func splitEnvironmentArgs(utils command.Utils, args []string, n int) (string, []string, error) {
+	if len(args) > n {
+		return args[0], args[1:], nil
+	}
+
+	envName, err := utils.ResolveEnvironmentName(nil)
+	if err != nil {
+		return "", nil, err
+	}
+	return envName, args, nil
+}
@@ -24,18 +24,30 @@ func NewDestroyCmd() *cobra.Command {
 		Short: "Destroy all resources in the specified environment",
 		Long: `Destroy all resources in the specified environment using the last deployed version of tfvars.
 This command will permanently delete all resources managed by Terraform.`,
-		Args: cobra.ExactArgs(1),
+		Args: cobra.MaximumNArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
-			opts.Environment, err = utils.GetEnvironment(args[0])
+			envName, err := utils.ResolveEnvironmentName(args)
 			if err != nil {
 				fmt.Printf("Error: %v\n", err)
 				os.Exit(1)
 			}
 
+			opts.Environment, err = utils.GetEnvironment(envName)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+			if err := requireExplicitEnvironment(cmd, opts.Environment, args); err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+
 			manager := destroy.NewManager(
 				utils.GetAWSClient(),
 				utils.GetFileUtils(),
 				utils.GetTerraformRunner(),
+				utils.GetIdentityResolver(),
+				utils.GetEnvironment,
 			)
 
 			if err := manager.Execute(cmd.Context(), &opts); err != nil {
@@ -48,6 +60,9 @@ This command will permanently delete all resources managed by Terraform.`,
 	destroyCmd.Flags().StringVar(&opts.VersionID, "version-id", "", "Specific version ID to use (defaults to last deployed version)")
 	destroyCmd.Flags().BoolVar(&opts.AutoApprove, "auto-approve", false, "Skip interactive approval")
 	destroyCmd.Flags().StringSliceVar(&opts.TerraformOpts, "options", nil, "Additional options for terraform destroy")
+	destroyCmd.Flags().BoolVar(&opts.Explain, "explain", false, "Show the result of every deployment policy rule")
+	destroyCmd.Flags().StringVar(&opts.BreakGlass, "break-glass", "", "Destroy inside a freeze window, giving the reason")
+	destroyCmd.Flags().BoolVar(&opts.Reinit, "reinit", false, "Re-initialize the backend if it does not match the environment")
 
 	return destroyCmd
 }
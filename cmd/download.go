@@ -31,9 +31,15 @@ func NewDownloadCmd() *cobra.Command {
 	downloadCmd := &cobra.Command{
 		Use:   "download [environment]",
 		Short: "Download tfvars file from S3 to local path",
-		Args:  cobra.ExactArgs(1),
+		Args:  cobra.MaximumNArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
-			if err := runDownload(cmd.Context(), utils, args[0], versionID, force); err != nil {
+			envName, err := utils.ResolveEnvironmentName(args)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+
+			if err := runDownload(cmd.Context(), utils, envName, versionID, force); err != nil {
 				fmt.Printf("Error: %v\n", err)
 				os.Exit(1)
 			}
@@ -99,6 +105,9 @@ func runDownload(ctx context.Context, utils command.Utils, envName, versionID st
 
 		if localHash == ver.Hash {
 			fmt.Println("\nLocal file is identical to the requested version. No download needed.")
+			if err := utils.GetStateManager().SetParentVersion(env.Name, ver.VersionID, ver.Hash); err != nil {
+				fmt.Printf("Warning: Failed to update local state: %v\n", err)
+			}
 			return nil
 		}
 
@@ -141,6 +150,11 @@ func runDownload(ctx context.Context, utils command.Utils, envName, versionID st
 		return fmt.Errorf("failed to write file: %w", err)
 	}
 
+	// Record the downloaded version as the parent of the local file
+	if err := utils.GetStateManager().SetParentVersion(env.Name, ver.VersionID, ver.Hash); err != nil {
+		fmt.Printf("Warning: Failed to update local state: %v\n", err)
+	}
+
 	fmt.Printf("\nSuccessfully downloaded to: %s\n", env.Local.TFVarsPath)
 	fmt.Printf("\nNext steps:\n")
 	fmt.Printf("  Review changes: cat %s\n", env.Local.TFVarsPath)
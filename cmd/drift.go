@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"tfvarenv/utils/command"
+	"tfvarenv/utils/drift"
+	"tfvarenv/utils/file"
+)
+
+type driftOptions struct {
+	All    bool
+	Format string
+	Output string
+}
+
+func NewDriftCmd() *cobra.Command {
+	utils, err := command.NewUtils()
+	if err != nil {
+		fmt.Printf("Error initializing command utils: %v\n", err)
+		os.Exit(1)
+	}
+
+	var opts driftOptions
+
+	driftCmd := &cobra.Command{
+		Use:   "drift [environments...]",
+		Short: "Detect infrastructure drift and undeployed tfvars",
+		Long: `Run 'terraform plan -detailed-exitcode' for each environment with its last deployed
+tfvars version (not the latest upload), and report infrastructure drift, tfvars versions
+uploaded but not deployed, and local tfvars files that differ from the deployed version.
+
+Exit codes: 0 everything in sync, 1 an environment could not be checked,
+2 infrastructure drift, 3 tfvars out of sync (undeployed versions or local changes).
+
+Example: tfvarenv drift --all
+         tfvarenv drift dev prod --format junit -o drift.xml`,
+		Run: func(cmd *cobra.Command, args []string) {
+			envNames, err := resolveDriftEnvironments(utils, args, opts.All)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(drift.ExitError)
+			}
+
+			opts.Output = utils.ResolvePath(opts.Output)
+			code, err := runDrift(cmd.Context(), utils, envNames, &opts, os.Stderr)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(drift.ExitError)
+			}
+			os.Exit(code)
+		},
+	}
+
+	driftCmd.Flags().BoolVar(&opts.All, "all", false, "Check all environments")
+	driftCmd.Flags().StringVar(&opts.Format, "format", drift.FormatTable, "Output format (table, json, junit)")
+	driftCmd.Flags().StringVarP(&opts.Output, "output", "o", "", "Write the report to a file")
+
+	return driftCmd
+}
+
+func resolveDriftEnvironments(utils command.Utils, args []string, all bool) ([]string, error) {
+	if all {
+		if len(args) > 0 {
+			return nil, fmt.Errorf("--all cannot be used with environment names")
+		}
+		names, err := utils.ListEnvironments()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list environments: %w", err)
+		}
+		sort.Strings(names)
+		return names, nil
+	}
+	if len(args) > 0 {
+		return args, nil
+	}
+
+	envName, err := utils.ResolveEnvironmentName(nil)
+	if err != nil {
+		return nil, err
+	}
+	return []string{envName}, nil
+}
+
+// runDrift writes progress to the progress writer so that the report on stdout stays machine-readable
+func runDrift(ctx context.Context, utils command.Utils, envNames []string, opts *driftOptions, progress io.Writer) (int, error) {
+	if opts.Format != drift.FormatTable && opts.Format != drift.FormatJSON && opts.Format != drift.FormatJUnit {
+		return 0, fmt.Errorf("unsupported format: %s (must be %s, %s or %s)",
+			opts.Format, drift.FormatTable, drift.FormatJSON, drift.FormatJUnit)
+	}
+
+	report := &drift.Report{CheckedAt: time.Now()}
+	driftManager := drift.NewManager(utils.GetAWSClient(), utils.GetFileUtils(), utils.GetTerraformRunner())
+
+	for _, envName := range envNames {
+		fmt.Fprintf(progress, "Checking environment '%s'...\n", envName)
+		env, err := utils.GetEnvironment(envName)
+		if err != nil {
+			report.Results = append(report.Results, drift.Result{
+				Environment: envName,
+				Status:      drift.StatusError,
+				Error:       err.Error(),
+			})
+			continue
+		}
+		report.Results = append(report.Results, *driftManager.Check(ctx, env))
+		if ctx.Err() != nil {
+			break
+		}
+	}
+
+	if opts.Output == "" {
+		if err := drift.Render(os.Stdout, report, opts.Format); err != nil {
+			return 0, err
+		}
+		return report.ExitCode(), nil
+	}
+
+	var buf bytes.Buffer
+	if err := drift.Render(&buf, report, opts.Format); err != nil {
+		return 0, err
+	}
+	writeOpts := &file.Options{
+		CreateDirs: true,
+		Overwrite:  true,
+	}
+	if err := utils.GetFileUtils().WriteFile(opts.Output, buf.Bytes(), writeOpts); err != nil {
+		return 0, fmt.Errorf("failed to write report: %w", err)
+	}
+	fmt.Printf("Wrote drift report of %d environments to %s\n", len(report.Results), opts.Output)
+	return report.ExitCode(), nil
+}
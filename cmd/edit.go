@@ -0,0 +1,237 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"tfvarenv/config"
+	"tfvarenv/utils/command"
+	"tfvarenv/utils/file"
+	"tfvarenv/utils/prompt"
+	"tfvarenv/utils/tfvars"
+	"tfvarenv/utils/version"
+)
+
+// editOptions represents edit command options
+type editOptions struct {
+	VersionID   string
+	Description string
+	Force       bool
+}
+
+func NewEditCmd() *cobra.Command {
+	utils, err := command.NewUtils()
+	if err != nil {
+		fmt.Printf("Error initializing command utils: %v\n", err)
+		os.Exit(1)
+	}
+
+	var opts editOptions
+
+	editCmd := &cobra.Command{
+		Use:   "edit [environment]",
+		Short: "Edit a remote tfvars version and upload the result",
+		Long: `Download a version of the tfvars file to a temporary file and open it in $EDITOR.
+After saving, the syntax is validated, the changed variables are shown and the result
+is uploaded as a new version. The local tfvars file is not modified.`,
+		Args: cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			envName, err := utils.ResolveEnvironmentName(args)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+
+			if err := runEdit(cmd.Context(), utils, envName, &opts); err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+		},
+	}
+
+	editCmd.Flags().StringVar(&opts.VersionID, "version-id", "", "Version ID to edit (defaults to the latest version)")
+	editCmd.Flags().StringVarP(&opts.Description, "description", "d", "", "Description for the new version")
+	editCmd.Flags().BoolVarP(&opts.Force, "force", "f", false, "Upload even if a newer version has been uploaded in the meantime")
+
+	return editCmd
+}
+
+func runEdit(ctx context.Context, utils command.Utils, envName string, opts *editOptions) error {
+	env, err := utils.GetEnvironment(envName)
+	if err != nil {
+		return fmt.Errorf("environment not found: %w", err)
+	}
+
+	versionManager := version.NewManager(utils.GetAWSClient(), utils.GetFileUtils(), env)
+
+	var baseVer *version.Version
+	if opts.VersionID != "" {
+		baseVer, err = versionManager.GetVersion(ctx, opts.VersionID)
+	} else {
+		baseVer, err = versionManager.GetLatestVersion(ctx)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get version information: %w", err)
+	}
+
+	baseContent, err := versionManager.GetVersionContent(ctx, baseVer.VersionID)
+	if err != nil {
+		return fmt.Errorf("failed to download version: %w", err)
+	}
+	base, err := tfvars.Parse(baseContent, "version "+version.ShortID(baseVer.VersionID))
+	if err != nil {
+		return err
+	}
+
+	// Prepare temporary file
+	editDir := filepath.Join(".tmp", env.Name, "edit")
+	editFile := filepath.Join(editDir, env.S3.TFVarsKey)
+	writeOpts := &file.Options{
+		CreateDirs: true,
+		Overwrite:  true,
+	}
+	if err := utils.GetFileUtils().WriteFile(editFile, baseContent, writeOpts); err != nil {
+		return fmt.Errorf("failed to write temporary file: %w", err)
+	}
+
+	fmt.Printf("Editing version %s of environment '%s'\n", version.ShortID(baseVer.VersionID), env.Name)
+
+	edited, err := editUntilValid(utils.GetFileUtils(), editFile)
+	if err != nil {
+		return fmt.Errorf("%w (edits kept in %s)", err, editFile)
+	}
+
+	if bytes.Equal(edited, baseContent) {
+		fmt.Println("No changes made.")
+		os.RemoveAll(editDir)
+		return nil
+	}
+
+	editedFile, err := tfvars.Parse(edited, editFile)
+	if err != nil {
+		return err
+	}
+
+	changes := tfvars.Diff(base, editedFile)
+	if len(changes) == 0 {
+		fmt.Println("\nNo variables were changed (formatting only).")
+		if !prompt.PromptYesNo("Do you want to upload the reformatted file anyway?", false) {
+			os.RemoveAll(editDir)
+			return nil
+		}
+	} else {
+		fmt.Printf("\nChanges against version %s:\n", version.ShortID(baseVer.VersionID))
+		displayChanges(changes)
+	}
+
+	// Make sure no one else has uploaded a version since the base version
+	if latestVer, err := versionManager.GetLatestVersion(ctx); err == nil && latestVer.VersionID != baseVer.VersionID {
+		fmt.Printf("\nWarning: Version %s is not the latest version.\n", version.ShortID(baseVer.VersionID))
+		fmt.Printf("  Latest Version: %s (uploaded at %s by %s)\n",
+			version.ShortID(latestVer.VersionID),
+			latestVer.Timestamp.Format("2006-01-02 15:04:05"),
+			latestVer.UploadedBy)
+		if !opts.Force {
+			return fmt.Errorf("upload conflict: use --force to upload anyway (edits kept in %s)", editFile)
+		}
+	}
+
+	description := opts.Description
+	if description == "" {
+		description = prompt.PromptString("\nEnter description for this version", defaultEditDescription(changes))
+	}
+
+	if !prompt.PromptYesNo("\nUpload this change?", true) {
+		return fmt.Errorf("upload cancelled by user (edits kept in %s)", editFile)
+	}
+
+	newVersion, err := uploadVersion(ctx, utils, env, &versionUploadInput{
+		Content:         edited,
+		Description:     description,
+		ParentVersionID: baseVer.VersionID,
+	})
+	if err != nil {
+		return fmt.Errorf("%w (edits kept in %s)", err, editFile)
+	}
+	os.RemoveAll(editDir)
+
+	fmt.Printf("\nSuccessfully uploaded to %s\n", env.GetFullS3Path())
+	displayUploadedVersion(newVersion)
+	printLocalFileHint(utils, env)
+
+	return nil
+}
+
+// editUntilValid opens the editor until the file is saved with valid syntax or the user gives up
+func editUntilValid(fileUtils file.Utils, path string) ([]byte, error) {
+	for {
+		if err := openEditor(path); err != nil {
+			return nil, err
+		}
+
+		content, err := fileUtils.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read edited file: %w", err)
+		}
+
+		err = tfvars.Validate(content, path)
+		if err == nil {
+			return content, nil
+		}
+
+		fmt.Printf("\n%v\n", err)
+		if !prompt.PromptYesNo("Do you want to re-open the editor?", true) {
+			return nil, fmt.Errorf("edited file is not valid")
+		}
+	}
+}
+
+func openEditor(path string) error {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+
+	// $EDITOR may contain arguments such as "code --wait"
+	parts := strings.Fields(editor)
+	cmd := exec.Command(parts[0], append(parts[1:], path)...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("editor exited with error: %w", err)
+	}
+	return nil
+}
+
+func defaultEditDescription(changes []tfvars.Change) string {
+	if len(changes) == 0 {
+		return "Reformat tfvars"
+	}
+
+	names := make([]string, 0, len(changes))
+	for _, c := range changes {
+		names = append(names, c.Name)
+	}
+	return "Update " + strings.Join(names, ", ")
+}
+
+// printLocalFileHint tells the user how to bring the local file up to date after a remote-only change
+func printLocalFileHint(utils command.Utils, env *config.Environment) {
+	if exists, err := utils.GetFileUtils().FileExists(env.Local.TFVarsPath); err != nil || !exists {
+		return
+	}
+	fmt.Printf("\nThe local file %s was not modified.\n", env.Local.TFVarsPath)
+	fmt.Printf("  Update local file: tfvarenv download %s\n", env.Name)
+}
@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"tfvarenv/utils/command"
+	"tfvarenv/utils/envvars"
+)
+
+func NewEnvCmd() *cobra.Command {
+	utils, err := command.NewUtils()
+	if err != nil {
+		fmt.Printf("Error initializing command utils: %v\n", err)
+		os.Exit(1)
+	}
+
+	var export bool
+
+	envCmd := &cobra.Command{
+		Use:   "env [environment]",
+		Short: "Show the environment variables of an environment",
+		Long: `Show the env_vars configured for an environment. Secret references are shown as configured.
+With --export, references are resolved and shell export statements are printed,
+including TF_DATA_DIR and ` + command.EnvironmentVariable + `.
+Example: tfvarenv env prod
+         eval "$(tfvarenv env prod --export)"
+         echo 'eval "$(tfvarenv env dev --export)"' > .envrc   # direnv`,
+		Args: cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			envName, err := utils.ResolveEnvironmentName(args)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+
+			if err := runEnv(cmd.Context(), utils, envName, export); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+		},
+	}
+
+	envCmd.Flags().BoolVar(&export, "export", false, "Print resolved values as shell export statements")
+
+	return envCmd
+}
+
+func runEnv(ctx context.Context, utils command.Utils, envName string, export bool) error {
+	env, err := utils.GetEnvironment(envName)
+	if err != nil {
+		return fmt.Errorf("environment not found: %w", err)
+	}
+
+	if export {
+		vars, err := environmentVariables(ctx, utils, env)
+		if err != nil {
+			return err
+		}
+		return envvars.WriteExports(os.Stdout, vars)
+	}
+
+	vars := envvars.NewManager(utils.GetAWSClient()).Variables(env)
+	if len(vars) == 0 {
+		fmt.Printf("No environment variables configured for environment '%s'\n", env.Name)
+		return nil
+	}
+
+	fmt.Printf("Environment variables for environment '%s':\n", env.Name)
+	for _, v := range vars {
+		fmt.Printf("  %s=%s\n", v.Name, v.Value)
+	}
+	return nil
+}
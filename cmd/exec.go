@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+
+	"github.com/spf13/cobra"
+
+	"tfvarenv/config"
+	"tfvarenv/utils/command"
+	"tfvarenv/utils/envvars"
+)
+
+func NewExecCmd() *cobra.Command {
+	utils, err := command.NewUtils()
+	if err != nil {
+		fmt.Printf("Error initializing command utils: %v\n", err)
+		os.Exit(1)
+	}
+
+	execCmd := &cobra.Command{
+		Use:   "exec [environment] -- [command...]",
+		Short: "Run a command with the environment variables of an environment",
+		Long: `Run any command with the env_vars of the environment, TF_DATA_DIR and ` + command.EnvironmentVariable + ` set.
+Secret references in env_vars are resolved before the command starts.
+Example: tfvarenv exec prod -- tflint
+         tfvarenv exec prod -- terraform output -json`,
+		Args: cobra.MinimumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			envArgs, cmdArgs := args, []string(nil)
+			if dash := cmd.ArgsLenAtDash(); dash >= 0 {
+				envArgs, cmdArgs = args[:dash], args[dash:]
+			}
+			if len(envArgs) > 1 || len(cmdArgs) == 0 {
+				fmt.Printf("Error: usage: %s\n", cmd.UseLine())
+				os.Exit(1)
+			}
+
+			envName, err := utils.ResolveEnvironmentName(envArgs)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+
+			exitCode, err := runExec(cmd.Context(), utils, envName, cmdArgs)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+			if exitCode != 0 {
+				os.Exit(exitCode)
+			}
+		},
+	}
+
+	return execCmd
+}
+
+func runExec(ctx context.Context, utils command.Utils, envName string, args []string) (int, error) {
+	env, err := utils.GetEnvironment(envName)
+	if err != nil {
+		return 1, fmt.Errorf("environment not found: %w", err)
+	}
+
+	vars, err := environmentVariables(ctx, utils, env)
+	if err != nil {
+		return 1, err
+	}
+
+	c := exec.CommandContext(ctx, args[0], args[1:]...)
+	// 呼び出し元のディレクトリで実行する
+	c.Dir = utils.ResolvePath(".")
+	c.Env = envvars.Environ(os.Environ(), vars)
+	c.Stdin = os.Stdin
+	c.Stdout = os.Stdout
+	c.Stderr = os.Stderr
+
+	if err := c.Run(); err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return exitErr.ExitCode(), nil
+		}
+		return 1, fmt.Errorf("failed to run %s: %w", args[0], err)
+	}
+	return 0, nil
+}
+
+// environmentVariables returns the variables exec and env set for the environment
+func environmentVariables(ctx context.Context, utils command.Utils, env *config.Environment) (map[string]string, error) {
+	vars, err := utils.GetTerraformRunner().EnvVars(ctx, env)
+	if err != nil {
+		return nil, err
+	}
+	vars[command.EnvironmentVariable] = env.Name
+	return vars, nil
+}
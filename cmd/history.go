@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -11,6 +13,8 @@ import (
 	"tfvarenv/config"
 	"tfvarenv/utils/command"
 	"tfvarenv/utils/deployment"
+	"tfvarenv/utils/identity"
+	"tfvarenv/utils/tfvars"
 	"tfvarenv/utils/version"
 )
 
@@ -30,7 +34,7 @@ func NewHistoryCmd() *cobra.Command {
 	historyCmd := &cobra.Command{
 		Use:   "history [environment]",
 		Short: "Show deployment history for an environment",
-		Args:  cobra.ExactArgs(1),
+		Args:  cobra.MaximumNArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
 			var sinceTime time.Time
 			if since != "" {
@@ -42,7 +46,13 @@ func NewHistoryCmd() *cobra.Command {
 				}
 			}
 
-			env, err := utils.GetEnvironment(args[0])
+			envName, err := utils.ResolveEnvironmentName(args)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+
+			env, err := utils.GetEnvironment(envName)
 			if err != nil {
 				fmt.Printf("Error: %v\n", err)
 				os.Exit(1)
@@ -70,29 +80,38 @@ func NewHistoryCmd() *cobra.Command {
 	historyCmd.Flags().BoolVar(&showAll, "all", false, "Show all entries")
 	historyCmd.Flags().StringVar(&since, "since", "", "Show entries since date (YYYY-MM-DD)")
 
+	historyCmd.AddCommand(newHistoryShowCmd(utils))
+	historyCmd.AddCommand(newHistoryMigrateCmd(utils))
+
 	return historyCmd
 }
 
 func runHistory(ctx context.Context, utils command.Utils, env *config.Environment, opts *deployment.QueryOptions) error {
 	deploymentManager := deployment.NewManager(utils.GetAWSClient(), env)
-	history, err := deploymentManager.GetHistory(ctx)
+	latest, err := deploymentManager.GetLatestInfo(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to get deployment history: %w", err)
 	}
 
+	// Filter deployments
+	deployments, err := deploymentManager.QueryDeployments(ctx, *opts)
+	if err != nil {
+		return fmt.Errorf("failed to query deployments: %w", err)
+	}
+
 	fmt.Printf("Deployment history for environment '%s':\n", env.Name)
-	if len(history.Deployments) == 0 {
+	if latest == nil && len(deployments) == 0 && opts.Since.IsZero() {
 		fmt.Println("No deployment history found")
 		return nil
 	}
 
-	if history.LatestDeployment != nil {
+	if latest != nil {
 		fmt.Printf("  Current Status: %s (last modified: %s)\n",
-			history.LatestDeployment.Status,
-			history.LatestDeployment.ModifiedTime.Format("2006-01-02 15:04:05"))
-		if history.LatestDeployment.Deployment != nil {
+			latest.Status,
+			latest.ModifiedTime.Format("2006-01-02 15:04:05"))
+		if latest.Deployment != nil {
 			fmt.Printf("  Latest Version: %s\n",
-				history.LatestDeployment.Deployment.VersionID[:8])
+				version.ShortID(latest.Deployment.VersionID))
 		}
 	}
 	fmt.Println()
@@ -107,12 +126,7 @@ func runHistory(ctx context.Context, utils command.Utils, env *config.Environmen
 		}
 	}
 
-	// Filter and display deployments
-	deployments, err := deploymentManager.QueryDeployments(ctx, *opts)
-	if err != nil {
-		return fmt.Errorf("failed to query deployments: %w", err)
-	}
-
+	// Display deployments
 	fmt.Printf("Deployment history")
 	if opts.Limit > 0 {
 		fmt.Printf(" (showing last %d entries)", opts.Limit)
@@ -126,17 +140,64 @@ func runHistory(ctx context.Context, utils command.Utils, env *config.Environmen
 
 	for _, deploy := range deployments {
 		latestMark := ""
-		if history.LatestDeployment != nil &&
-			history.LatestDeployment.Deployment != nil &&
-			history.LatestDeployment.Deployment.Timestamp.Equal(deploy.Timestamp) {
+		if isLatestDeployment(latest, &deploy) {
 			latestMark = " (Latest)"
 		}
 
 		fmt.Printf("\n%s%s\n", deploy.Timestamp.Format("2006-01-02 15:04:05"), latestMark)
-		fmt.Printf("  Command: terraform %s\n", deploy.Command)
-		fmt.Printf("  Version: %s\n", deploy.VersionID[:8])
+		fmt.Printf("  ID: %s\n", deploy.ID)
+		if deploy.BreakGlass != nil {
+			fmt.Printf("  !!! BREAK-GLASS: %s (freeze window: %s)\n", deploy.BreakGlass.Reason, deploy.BreakGlass.FreezeWindow)
+		}
+		switch deploy.Command {
+		case deployment.CommandProtect, deployment.CommandUnprotect:
+			fmt.Printf("  Command: tfvarenv %s\n", deploy.Command)
+			if reason := deploy.Parameters["Reason"]; reason != "" {
+				fmt.Printf("  Reason: %s\n", reason)
+			}
+		default:
+			binary := deploy.TerraformBinary
+			if binary == "" {
+				binary = "terraform"
+			}
+			fmt.Printf("  Command: %s %s\n", binary, deploy.Command)
+		}
+		if deploy.TerraformVersion != "" {
+			fmt.Printf("  Terraform Version: %s\n", deploy.TerraformVersion)
+		}
+		if deploy.VersionID != "" {
+			fmt.Printf("  Version: %s\n", version.ShortID(deploy.VersionID))
+		}
+		if args := deploy.Parameters["Args"]; args != "" {
+			fmt.Printf("  Arguments: %s\n", args)
+		}
+		if check := deploy.PlanCheck; check != nil {
+			forced := ""
+			if check.Forced {
+				forced = " (plan rules overridden with --force)"
+			}
+			fmt.Printf("  Plan: %s%s\n", check.Summary, forced)
+			if len(check.Replaced) > 0 {
+				fmt.Printf("  Replaced: %s\n", strings.Join(check.Replaced, ", "))
+			}
+			if len(check.Deleted) > 0 {
+				fmt.Printf("  Deleted: %s\n", strings.Join(check.Deleted, ", "))
+			}
+		}
 		fmt.Printf("  By: %s\n", deploy.DeployedBy)
+		if id := deploy.Identity; id != nil {
+			printIdentity(id)
+		}
+		if deploy.Changes != nil && deploy.PlanCheck == nil {
+			fmt.Printf("  Changes: %s\n", deploy.Changes)
+		}
 		fmt.Printf("  Status: %s\n", deploy.Status)
+		if deploy.ExitCode != nil {
+			fmt.Printf("  Exit Code: %d\n", *deploy.ExitCode)
+		}
+		if deploy.Duration > 0 {
+			fmt.Printf("  Duration: %s\n", deploy.Duration.Round(time.Second))
+		}
 
 		// Add version information if available
 		if ver, ok := versionMap[deploy.VersionID]; ok {
@@ -150,9 +211,9 @@ func runHistory(ctx context.Context, utils command.Utils, env *config.Environmen
 		}
 	}
 
-	// Show summary statistics
-	stats, err := deploymentManager.GetStats(ctx)
-	if err == nil && stats.TotalDeployments > 1 {
+	// Show summary statistics of the entries shown
+	stats := deployment.NewStats(deployments)
+	if stats.TotalDeployments > 1 {
 		fmt.Printf("\nDeployment Statistics:\n")
 		fmt.Printf("  Total Deployments: %d\n", stats.TotalDeployments)
 		fmt.Printf("  Successful: %d\n", stats.SuccessfulCount)
@@ -164,3 +225,270 @@ func runHistory(ctx context.Context, utils command.Utils, env *config.Environmen
 
 	return nil
 }
+
+// printIdentity shows the caller, git and CI context of a record
+func printIdentity(id *identity.Identity) {
+	if id.CallerArn != "" && id.CallerArn != id.Deployer {
+		fmt.Printf("  Caller: %s\n", id.CallerArn)
+	}
+	if id.Git != nil {
+		dirty := ""
+		if id.Git.Dirty {
+			dirty = " (uncommitted changes)"
+		}
+		commit := id.Git.Commit
+		if len(commit) > 8 {
+			commit = commit[:8]
+		}
+		if id.Git.Branch != "" {
+			fmt.Printf("  Git: %s on %s%s\n", commit, id.Git.Branch, dirty)
+		} else {
+			fmt.Printf("  Git: %s%s\n", commit, dirty)
+		}
+	}
+	if id.CI != nil {
+		run := id.CI.RunURL
+		if run == "" {
+			run = id.CI.RunID
+		}
+		fmt.Printf("  CI: %s %s\n", id.CI.Provider, run)
+	}
+}
+
+func newHistoryShowCmd(utils command.Utils) *cobra.Command {
+	return &cobra.Command{
+		Use:   "show [environment] [deployment-id]",
+		Short: "Show a deployment record in detail",
+		Long: `Show a deployment record with its identity, plan summary and the tfvars changes
+since the previous deployment. The deployment ID may be abbreviated as long as it is unique.
+Example: tfvarenv history show prod 01J9ZQ3N6X8V2K4T5R7W9YBCDE`,
+		Args: cobra.RangeArgs(1, 2),
+		Run: func(cmd *cobra.Command, args []string) {
+			envName, rest, err := splitEnvironmentArgs(utils, args, 1)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+			if err := runHistoryShow(cmd.Context(), utils, envName, rest[0]); err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+		},
+	}
+}
+
+func newHistoryMigrateCmd(utils command.Utils) *cobra.Command {
+	var all bool
+
+	migrateCmd := &cobra.Command{
+		Use:   "migrate [environment]",
+		Short: "Convert a single-file deployment history to individual records",
+		Long: `Move the records of the old single-file history (.<environment>.deployments.json) to one
+object per record with a head pointer, and remove the old file. Until then both are read.
+Example: tfvarenv history migrate prod
+         tfvarenv history migrate --all`,
+		Args: cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			var envNames []string
+			if all {
+				names, err := utils.ListEnvironments()
+				if err != nil {
+					fmt.Printf("Error: failed to list environments: %v\n", err)
+					os.Exit(1)
+				}
+				sort.Strings(names)
+				envNames = names
+			} else {
+				envName, err := utils.ResolveEnvironmentName(args)
+				if err != nil {
+					fmt.Printf("Error: %v\n", err)
+					os.Exit(1)
+				}
+				envNames = []string{envName}
+			}
+
+			if err := runHistoryMigrate(cmd.Context(), utils, envNames); err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+		},
+	}
+
+	migrateCmd.Flags().BoolVar(&all, "all", false, "Migrate all environments")
+
+	return migrateCmd
+}
+
+func runHistoryMigrate(ctx context.Context, utils command.Utils, envNames []string) error {
+	for _, envName := range envNames {
+		env, err := utils.GetEnvironment(envName)
+		if err != nil {
+			return err
+		}
+
+		moved, err := deployment.NewManager(utils.GetAWSClient(), env).Migrate(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to migrate deployment history of %s: %w", env.Name, err)
+		}
+		if moved == 0 {
+			fmt.Printf("%s: nothing to migrate\n", env.Name)
+			continue
+		}
+		fmt.Printf("%s: migrated %d record(s) to s3://%s/%s\n", env.Name, moved, env.S3.Bucket, env.GetDeploymentPrefix())
+	}
+	return nil
+}
+
+func runHistoryShow(ctx context.Context, utils command.Utils, envName, id string) error {
+	env, err := utils.GetEnvironment(envName)
+	if err != nil {
+		return err
+	}
+
+	deploymentManager := deployment.NewManager(utils.GetAWSClient(), env)
+	deploy, err := deploymentManager.GetRecord(ctx, id)
+	if err != nil {
+		return err
+	}
+	latest, err := deploymentManager.GetLatestInfo(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get deployment history: %w", err)
+	}
+
+	latestMark := ""
+	if isLatestDeployment(latest, deploy) {
+		latestMark = " (Latest)"
+	}
+	fmt.Printf("Deployment %s%s\n", deploy.ID, latestMark)
+	fmt.Printf("  Environment: %s\n", deploy.Environment)
+	fmt.Printf("  Time: %s\n", deploy.Timestamp.Format("2006-01-02 15:04:05"))
+	fmt.Printf("  Command: %s\n", deploy.Command)
+	fmt.Printf("  Status: %s\n", deploy.Status)
+	if deploy.ExitCode != nil {
+		fmt.Printf("  Exit Code: %d\n", *deploy.ExitCode)
+	}
+	if deploy.Duration > 0 {
+		fmt.Printf("  Duration: %s\n", deploy.Duration.Round(time.Second))
+	}
+	if deploy.TerraformBinary != "" {
+		fmt.Printf("  Terraform: %s %s\n", deploy.TerraformBinary, deploy.TerraformVersion)
+	}
+
+	versionManager := version.NewManager(utils.GetAWSClient(), utils.GetFileUtils(), env)
+	if deploy.VersionID != "" {
+		fmt.Printf("  Version: %s\n", deploy.VersionID)
+		if ver, err := versionManager.GetVersion(ctx, deploy.VersionID); err == nil && ver.Description != "" {
+			fmt.Printf("  Description: %s\n", ver.Description)
+		}
+	}
+
+	fmt.Printf("  By: %s\n", deploy.DeployedBy)
+	if id := deploy.Identity; id != nil {
+		printIdentity(id)
+		if id.AccountID != "" {
+			fmt.Printf("  Account: %s\n", id.AccountID)
+		}
+		if id.Host != "" {
+			fmt.Printf("  Host: %s\n", id.Host)
+		}
+		if id.CI != nil && id.CI.Actor != "" {
+			fmt.Printf("  CI Actor: %s\n", id.CI.Actor)
+		}
+		if id.CI != nil && id.CI.RunURL != "" {
+			fmt.Printf("  Logs: %s\n", id.CI.RunURL)
+		}
+	}
+	if deploy.BreakGlass != nil {
+		fmt.Printf("  !!! BREAK-GLASS: %s (freeze window: %s)\n", deploy.BreakGlass.Reason, deploy.BreakGlass.FreezeWindow)
+	}
+
+	if len(deploy.Parameters) > 0 {
+		fmt.Println("  Parameters:")
+		keys := make([]string, 0, len(deploy.Parameters))
+		for k := range deploy.Parameters {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			fmt.Printf("    %s: %s\n", k, deploy.Parameters[k])
+		}
+	}
+
+	if check := deploy.PlanCheck; check != nil {
+		fmt.Printf("  Plan: %s\n", check.Summary)
+		if check.Forced {
+			fmt.Println("    Plan rules overridden with --force")
+		}
+		for _, address := range check.Replaced {
+			fmt.Printf("    replace %s\n", address)
+		}
+		for _, address := range check.Deleted {
+			fmt.Printf("    delete %s\n", address)
+		}
+		for _, v := range check.Violations {
+			fmt.Printf("    violation: %s\n", v)
+		}
+	} else if deploy.Changes != nil {
+		fmt.Printf("  Plan: %s\n", deploy.Changes)
+	}
+
+	if deploy.ErrorMessage != "" {
+		fmt.Printf("  Error: %s\n", deploy.ErrorMessage)
+	}
+
+	if deploy.VersionID == "" {
+		return nil
+	}
+	return showDeploymentDiff(ctx, utils, env, deploymentManager, deploy)
+}
+
+// showDeploymentDiff prints the tfvars changes since the previous successful apply
+func showDeploymentDiff(ctx context.Context, utils command.Utils, env *config.Environment, deploymentManager deployment.Manager, deploy *deployment.Record) error {
+	records, err := deploymentManager.QueryDeployments(ctx, deployment.QueryOptions{
+		Before:  deploy.Timestamp,
+		Command: deployment.CommandApply,
+		Status:  deployment.StatusSuccess,
+		Limit:   2,
+	})
+	if err != nil {
+		return err
+	}
+	var previous *deployment.Record
+	for i, d := range records {
+		if d.ID != deploy.ID && d.Timestamp.Before(deploy.Timestamp) {
+			previous = &records[i]
+			break
+		}
+	}
+
+	newFile, _, err := loadRemoteTFVars(ctx, utils, env, deploy.VersionID)
+	if err != nil {
+		return err
+	}
+	var oldFile *tfvars.File
+	if previous != nil {
+		if previous.VersionID == deploy.VersionID {
+			fmt.Printf("\nNo tfvars changes since the previous deployment %s\n", previous.ID)
+			return nil
+		}
+		if oldFile, _, err = loadRemoteTFVars(ctx, utils, env, previous.VersionID); err != nil {
+			return err
+		}
+		fmt.Printf("\nChanges since the previous deployment %s (version %s):\n", previous.ID, version.ShortID(previous.VersionID))
+	} else {
+		fmt.Println("\nVariables (no previous deployment):")
+	}
+
+	changes := tfvars.Diff(oldFile, newFile)
+	if len(changes) == 0 {
+		fmt.Println("  No variable changes")
+		return nil
+	}
+	displayChanges(changes)
+	return nil
+}
+
+// isLatestDeployment reports whether the record is the latest deployment of the environment
+func isLatestDeployment(latest *deployment.LatestInfo, record *deployment.Record) bool {
+	return latest != nil && latest.Deployment != nil && latest.Deployment.ID == record.ID
+}
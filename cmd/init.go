@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/spf13/cobra"
@@ -43,7 +44,8 @@ func runInit(ctx context.Context, utils command.Utils) error {
 		return fmt.Errorf("failed to check config file: %w", err)
 	}
 	if exists {
-		return fmt.Errorf("configuration file already exists")
+		path, _ := filepath.Abs(".tfvarenv.json")
+		return fmt.Errorf("configuration file already exists at %s", path)
 	}
 
 	reader := bufio.NewReader(os.Stdin)
@@ -120,6 +122,8 @@ func updateGitignore(fileUtils file.Utils) error {
 		".terraform/",
 		".tmp/",
 		".backups/",
+		".tfvarenv.state.json",
+		".tfvarenv/",
 	}
 
 	content, err := fileUtils.ReadFile(".gitignore")
@@ -51,6 +51,9 @@ func runList(ctx context.Context, utils command.Utils) error {
 		fmt.Printf("  AWS Account: %s (%s)\n", env.AWS.AccountID, env.AWS.Region)
 		fmt.Printf("  S3 Path: %s\n", env.GetS3Path())
 		fmt.Printf("  Local Path: %s\n", env.Local.TFVarsPath)
+		if env.WorkingDir != "" {
+			fmt.Printf("  Working Directory: %s\n", env.WorkingDir)
+		}
 
 		// Get version information
 		versionManager := version.NewManager(utils.GetAWSClient(), utils.GetFileUtils(), env)
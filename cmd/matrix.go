@@ -0,0 +1,174 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"tfvarenv/config"
+	"tfvarenv/utils/command"
+	"tfvarenv/utils/deployment"
+	"tfvarenv/utils/file"
+	"tfvarenv/utils/matrix"
+	"tfvarenv/utils/tfvars"
+	"tfvarenv/utils/version"
+)
+
+const (
+	matrixSourceLatest   = "latest"
+	matrixSourceDeployed = "deployed"
+)
+
+// matrixOptions represents matrix command options
+type matrixOptions struct {
+	Variables     []string
+	Environments  []string
+	Source        string
+	Format        string
+	Output        string
+	ShowSensitive bool
+}
+
+func NewMatrixCmd() *cobra.Command {
+	utils, err := command.NewUtils()
+	if err != nil {
+		fmt.Printf("Error initializing command utils: %v\n", err)
+		os.Exit(1)
+	}
+
+	var opts matrixOptions
+
+	matrixCmd := &cobra.Command{
+		Use:   "matrix",
+		Short: "Compare variables across all environments",
+		Long: `Show a variable × environment table of the latest (or deployed) version of every environment.
+Values that differ from the majority of environments are highlighted, and sensitive values are masked.
+Example: tfvarenv matrix --vars instance_type,min_size
+         tfvarenv matrix --source deployed --format html -o matrix.html`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			opts.Output = utils.ResolvePath(opts.Output)
+			if err := runMatrix(cmd.Context(), utils, &opts); err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+		},
+	}
+
+	matrixCmd.Flags().StringSliceVar(&opts.Variables, "vars", nil, "Variables to include (default: all)")
+	matrixCmd.Flags().StringSliceVar(&opts.Environments, "envs", nil, "Environments to include, in column order (default: all, sorted by name)")
+	matrixCmd.Flags().StringVar(&opts.Source, "source", matrixSourceLatest, "Version to compare (latest, deployed)")
+	matrixCmd.Flags().StringVar(&opts.Format, "format", matrix.FormatTable, "Output format (table, markdown, csv, html)")
+	matrixCmd.Flags().StringVarP(&opts.Output, "output", "o", "", "Write the report to a file")
+	matrixCmd.Flags().BoolVar(&opts.ShowSensitive, "show-sensitive", false, "Do not mask sensitive values")
+
+	return matrixCmd
+}
+
+func runMatrix(ctx context.Context, utils command.Utils, opts *matrixOptions) error {
+	if opts.Source != matrixSourceLatest && opts.Source != matrixSourceDeployed {
+		return fmt.Errorf("invalid value for --source: %s (must be %s or %s)",
+			opts.Source, matrixSourceLatest, matrixSourceDeployed)
+	}
+
+	envNames := opts.Environments
+	if len(envNames) == 0 {
+		names, err := utils.ListEnvironments()
+		if err != nil {
+			return fmt.Errorf("failed to list environments: %w", err)
+		}
+		sort.Strings(names)
+		envNames = names
+	}
+
+	files := make(map[string]*tfvars.File)
+	missing := make(map[string]string)
+	sensitive := make(map[string]bool)
+	loadedDirs := make(map[string]bool)
+	for _, envName := range envNames {
+		env, err := utils.GetEnvironment(envName)
+		if err != nil {
+			missing[envName] = err.Error()
+			continue
+		}
+
+		// 変数定義の sensitive = true はマスク対象にする
+		if dir := env.GetWorkingDir(); !loadedDirs[dir] {
+			loadedDirs[dir] = true
+			// 読めなかったファイルがあっても、読めた宣言はマスクする
+			declared, err := matrix.LoadSensitiveVariables(dir)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: Failed to read some variable declarations: %v\n", err)
+			}
+			for name := range declared {
+				sensitive[name] = true
+			}
+		}
+
+		f, err := loadMatrixTFVars(ctx, utils, env, opts.Source)
+		if err != nil {
+			missing[envName] = err.Error()
+			continue
+		}
+		files[envName] = f
+	}
+
+	result := matrix.Build(envNames, files, &matrix.Options{
+		Variables:     opts.Variables,
+		Sensitive:     sensitive,
+		ShowSensitive: opts.ShowSensitive,
+	})
+	result.Missing = missing
+
+	if opts.Output == "" {
+		return matrix.Render(os.Stdout, result, opts.Format)
+	}
+
+	var buf bytes.Buffer
+	if err := matrix.Render(&buf, result, opts.Format); err != nil {
+		return err
+	}
+	writeOpts := &file.Options{
+		CreateDirs: true,
+		Overwrite:  true,
+	}
+	if err := utils.GetFileUtils().WriteFile(opts.Output, buf.Bytes(), writeOpts); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	fmt.Printf("Wrote matrix of %d environments to %s\n", len(envNames), opts.Output)
+	return nil
+}
+
+func loadMatrixTFVars(ctx context.Context, utils command.Utils, env *config.Environment, source string) (*tfvars.File, error) {
+	versionManager := version.NewManager(utils.GetAWSClient(), utils.GetFileUtils(), env)
+
+	var versionID string
+	if source == matrixSourceDeployed {
+		deploymentManager := deployment.NewManager(utils.GetAWSClient(), env)
+		deploy, err := deploymentManager.GetActiveDeploymentAt(ctx, time.Now())
+		if err != nil {
+			return nil, err
+		}
+		if deploy == nil {
+			return nil, fmt.Errorf("not deployed")
+		}
+		versionID = deploy.VersionID
+	} else {
+		latestVer, err := versionManager.GetLatestVersion(ctx)
+		if err != nil {
+			return nil, err
+		}
+		versionID = latestVer.VersionID
+	}
+
+	content, err := versionManager.GetVersionContent(ctx, versionID)
+	if err != nil {
+		return nil, err
+	}
+	return tfvars.Parse(content, fmt.Sprintf("%s version %s", env.Name, versionID))
+}
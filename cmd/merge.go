@@ -0,0 +1,368 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"tfvarenv/config"
+	"tfvarenv/utils/command"
+	"tfvarenv/utils/file"
+	"tfvarenv/utils/prompt"
+	"tfvarenv/utils/state"
+	"tfvarenv/utils/tfvars"
+	"tfvarenv/utils/version"
+)
+
+const (
+	mergeStrategyInteractive = "interactive"
+	mergeStrategyMarkers     = "markers"
+)
+
+// mergeOptions represents merge command options
+type mergeOptions struct {
+	BaseVersionID string
+	Strategy      string
+	Description   string
+	Continue      bool
+}
+
+func NewMergeCmd() *cobra.Command {
+	utils, err := command.NewUtils()
+	if err != nil {
+		fmt.Printf("Error initializing command utils: %v\n", err)
+		os.Exit(1)
+	}
+
+	var opts mergeOptions
+
+	mergeCmd := &cobra.Command{
+		Use:   "merge [environment]",
+		Short: "Merge the latest remote version into the local tfvars file",
+		Long: `Merge the latest remote version into the local tfvars file and upload the result.
+The merge is done per variable against the common ancestor, which is the version the local file was downloaded from.
+Conflicting variables are resolved interactively, or written to the local file with conflict markers
+when --strategy markers is given. After editing the markers, run 'tfvarenv merge [environment] --continue'.`,
+		Args: cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			envName, err := utils.ResolveEnvironmentName(args)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+
+			if err := runMerge(cmd.Context(), utils, envName, &opts); err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+		},
+	}
+
+	mergeCmd.Flags().StringVar(&opts.BaseVersionID, "base", "", "Version ID of the common ancestor (defaults to the parent of the local file)")
+	mergeCmd.Flags().StringVar(&opts.Strategy, "strategy", mergeStrategyInteractive, "Conflict resolution strategy (interactive, markers)")
+	mergeCmd.Flags().StringVarP(&opts.Description, "description", "d", "", "Description for the merged version")
+	mergeCmd.Flags().BoolVar(&opts.Continue, "continue", false, "Upload the local file after resolving conflict markers")
+
+	return mergeCmd
+}
+
+func runMerge(ctx context.Context, utils command.Utils, envName string, opts *mergeOptions) error {
+	if opts.Strategy != mergeStrategyInteractive && opts.Strategy != mergeStrategyMarkers {
+		return fmt.Errorf("invalid strategy: %s (must be %s or %s)", opts.Strategy, mergeStrategyInteractive, mergeStrategyMarkers)
+	}
+
+	env, err := utils.GetEnvironment(envName)
+	if err != nil {
+		return fmt.Errorf("environment not found: %w", err)
+	}
+
+	envState, err := utils.GetStateManager().GetEnvironmentState(env.Name)
+	if err != nil {
+		return fmt.Errorf("failed to read local state: %w", err)
+	}
+
+	if opts.Continue {
+		return continueMerge(ctx, utils, env, envState, opts)
+	}
+	if envState.Merge != nil {
+		return fmt.Errorf("a merge is already in progress; resolve the conflicts and run 'tfvarenv merge %s --continue'", env.Name)
+	}
+
+	fileUtils := utils.GetFileUtils()
+	versionManager := version.NewManager(utils.GetAWSClient(), fileUtils, env)
+
+	latestVer, err := versionManager.GetLatestVersion(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get latest version: %w", err)
+	}
+
+	localContent, err := fileUtils.ReadFile(env.Local.TFVarsPath)
+	if err != nil {
+		return fmt.Errorf("failed to read local file: %w", err)
+	}
+	if file.HashContent(localContent) == latestVer.Hash {
+		fmt.Println("Local file is identical to the latest version. Nothing to merge.")
+		if err := utils.GetStateManager().SetParentVersion(env.Name, latestVer.VersionID, latestVer.Hash); err != nil {
+			fmt.Printf("Warning: Failed to update local state: %v\n", err)
+		}
+		return nil
+	}
+
+	baseVersionID := opts.BaseVersionID
+	if baseVersionID == "" {
+		baseVersionID = envState.ParentVersionID
+	}
+	if baseVersionID == latestVer.VersionID {
+		fmt.Printf("Local file is based on the latest version %s. Nothing to merge.\n", version.ShortID(latestVer.VersionID))
+		fmt.Printf("Use 'tfvarenv upload %s' to upload the local changes.\n", env.Name)
+		return nil
+	}
+
+	// Load the three sides of the merge
+	local, err := tfvars.Parse(localContent, env.Local.TFVarsPath)
+	if err != nil {
+		return err
+	}
+
+	remoteContent, err := versionManager.GetVersionContent(ctx, latestVer.VersionID)
+	if err != nil {
+		return fmt.Errorf("failed to download latest version: %w", err)
+	}
+	remote, err := tfvars.Parse(remoteContent, "remote version "+version.ShortID(latestVer.VersionID))
+	if err != nil {
+		return err
+	}
+
+	var base *tfvars.File
+	if baseVersionID != "" {
+		baseVer, err := versionManager.GetVersion(ctx, baseVersionID)
+		if err != nil {
+			return fmt.Errorf("failed to get base version: %w", err)
+		}
+		baseVersionID = baseVer.VersionID
+		baseContent, err := versionManager.GetVersionContent(ctx, baseVersionID)
+		if err != nil {
+			return fmt.Errorf("failed to download base version: %w", err)
+		}
+		if base, err = tfvars.Parse(baseContent, "base version "+version.ShortID(baseVersionID)); err != nil {
+			return err
+		}
+	} else {
+		fmt.Println("Warning: No common ancestor is known. Every differing variable will be treated as a conflict.")
+	}
+
+	fmt.Printf("\nMerging into local file %s:\n", env.Local.TFVarsPath)
+	if baseVersionID != "" {
+		fmt.Printf("  Base Version: %s\n", version.ShortID(baseVersionID))
+	}
+	fmt.Printf("  Remote Version: %s (uploaded at %s by %s)\n",
+		version.ShortID(latestVer.VersionID),
+		latestVer.Timestamp.Format("2006-01-02 15:04:05"),
+		latestVer.UploadedBy)
+
+	result, err := tfvars.Merge(base, local, remote)
+	if err != nil {
+		return fmt.Errorf("failed to merge: %w", err)
+	}
+
+	backupPath, err := backupLocalFile(fileUtils, env)
+	if err != nil {
+		return err
+	}
+
+	if len(result.Conflicts) > 0 {
+		fmt.Printf("\n%d conflicting variable(s) found.\n", len(result.Conflicts))
+		if opts.Strategy == mergeStrategyMarkers {
+			return writeConflictMarkers(utils, env, result, baseVersionID, latestVer, backupPath)
+		}
+		if err := resolveConflictsInteractively(result); err != nil {
+			return err
+		}
+	}
+
+	// 競合の解決中にリモートが更新されていないか確認する
+	if current, err := versionManager.GetLatestVersion(ctx); err != nil {
+		return fmt.Errorf("failed to get latest version: %w", err)
+	} else if current.VersionID != latestVer.VersionID {
+		return fmt.Errorf("the remote file was updated during the merge (latest version %s); run 'tfvarenv merge %s' again",
+			version.ShortID(current.VersionID), env.Name)
+	}
+
+	merged := result.File.Bytes()
+	writeOpts := &file.Options{
+		CreateDirs: true,
+		Overwrite:  true,
+	}
+	if err := fileUtils.WriteFile(env.Local.TFVarsPath, merged, writeOpts); err != nil {
+		return fmt.Errorf("failed to write merged file: %w", err)
+	}
+
+	return uploadMergeResult(ctx, utils, env, merged, remote, envState.ParentVersionID, latestVer, opts.Description)
+}
+
+func continueMerge(ctx context.Context, utils command.Utils, env *config.Environment, envState *state.EnvironmentState, opts *mergeOptions) error {
+	if envState.Merge == nil {
+		return fmt.Errorf("no merge in progress for environment '%s'", env.Name)
+	}
+
+	content, err := utils.GetFileUtils().ReadFile(env.Local.TFVarsPath)
+	if err != nil {
+		return fmt.Errorf("failed to read local file: %w", err)
+	}
+	if tfvars.HasConflictMarkers(content) {
+		return fmt.Errorf("%s still contains conflict markers", env.Local.TFVarsPath)
+	}
+	if err := tfvars.Validate(content, env.Local.TFVarsPath); err != nil {
+		return err
+	}
+
+	versionManager := version.NewManager(utils.GetAWSClient(), utils.GetFileUtils(), env)
+	latestVer, err := versionManager.GetLatestVersion(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get latest version: %w", err)
+	}
+	if latestVer.VersionID != envState.Merge.RemoteVersionID {
+		return fmt.Errorf("the remote file has been updated again (latest version %s); restore %s and run 'tfvarenv merge %s' again",
+			version.ShortID(latestVer.VersionID), envState.Merge.BackupPath, env.Name)
+	}
+
+	remoteContent, err := versionManager.GetVersionContent(ctx, latestVer.VersionID)
+	if err != nil {
+		return fmt.Errorf("failed to download latest version: %w", err)
+	}
+	remote, err := tfvars.Parse(remoteContent, "remote version "+version.ShortID(latestVer.VersionID))
+	if err != nil {
+		return err
+	}
+
+	return uploadMergeResult(ctx, utils, env, content, remote, envState.ParentVersionID, latestVer, opts.Description)
+}
+
+func writeConflictMarkers(utils command.Utils, env *config.Environment, result *tfvars.MergeResult, baseVersionID string, latestVer *version.Version, backupPath string) error {
+	content := result.ConflictMarkers("local", fmt.Sprintf("remote (%s)", version.ShortID(latestVer.VersionID)))
+	writeOpts := &file.Options{
+		CreateDirs: true,
+		Overwrite:  true,
+	}
+	if err := utils.GetFileUtils().WriteFile(env.Local.TFVarsPath, content, writeOpts); err != nil {
+		return fmt.Errorf("failed to write conflict markers: %w", err)
+	}
+
+	mergeState := &state.MergeState{
+		BaseVersionID:   baseVersionID,
+		RemoteVersionID: latestVer.VersionID,
+		BackupPath:      backupPath,
+		StartedAt:       time.Now(),
+	}
+	if err := utils.GetStateManager().SetMergeState(env.Name, mergeState); err != nil {
+		return fmt.Errorf("failed to record merge state: %w", err)
+	}
+
+	fmt.Printf("Conflict markers were written to %s:\n", env.Local.TFVarsPath)
+	for _, c := range result.Conflicts {
+		fmt.Printf("  %s\n", c.Name)
+	}
+	fmt.Printf("\nNext steps:\n")
+	fmt.Printf("  Resolve the conflicts in %s\n", env.Local.TFVarsPath)
+	fmt.Printf("  Upload the result: tfvarenv merge %s --continue\n", env.Name)
+	fmt.Printf("  Original local file: %s\n", backupPath)
+
+	return nil
+}
+
+func resolveConflictsInteractively(result *tfvars.MergeResult) error {
+	conflicts := append([]tfvars.Conflict(nil), result.Conflicts...)
+	for i, c := range conflicts {
+		fmt.Printf("\nConflict %d/%d: %s\n", i+1, len(conflicts), c.Name)
+		fmt.Printf("  [b]ase:   %s\n", variableValue(c.Base))
+		fmt.Printf("  [l]ocal:  %s\n", variableValue(c.Local))
+		fmt.Printf("  [r]emote: %s\n", variableValue(c.Remote))
+
+		var value *tfvars.Variable
+		switch prompt.PromptChoice("Which value do you want to keep?", []string{"l", "r", "b"}, "") {
+		case "l":
+			value = c.Local
+		case "r":
+			value = c.Remote
+		case "b":
+			value = c.Base
+		default:
+			return fmt.Errorf("merge cancelled by user")
+		}
+
+		if err := result.Resolve(c.Name, value); err != nil {
+			return fmt.Errorf("failed to resolve %s: %w", c.Name, err)
+		}
+	}
+	return nil
+}
+
+// uploadMergeResult uploads the merged content with the latest version and the parent of the local file as parents
+func uploadMergeResult(ctx context.Context, utils command.Utils, env *config.Environment, content []byte, remote *tfvars.File, localParentVersionID string, latestVer *version.Version, description string) error {
+	merged, err := tfvars.Parse(content, env.Local.TFVarsPath)
+	if err != nil {
+		return err
+	}
+
+	changes := tfvars.Diff(remote, merged)
+	if len(changes) == 0 {
+		fmt.Println("\nMerged file is identical to the latest version. No upload needed.")
+		if err := utils.GetStateManager().SetParentVersion(env.Name, latestVer.VersionID, latestVer.Hash); err != nil {
+			fmt.Printf("Warning: Failed to update local state: %v\n", err)
+		}
+		return nil
+	}
+
+	fmt.Printf("\nChanges on top of remote version %s:\n", version.ShortID(latestVer.VersionID))
+	displayChanges(changes)
+
+	if description == "" {
+		description = fmt.Sprintf("Merge local changes into %s", version.ShortID(latestVer.VersionID))
+	}
+
+	newVersion, err := uploadVersion(ctx, utils, env, &versionUploadInput{
+		Content:              content,
+		Description:          description,
+		ParentVersionID:      latestVer.VersionID,
+		MergeParentVersionID: localParentVersionID,
+		FromLocalFile:        true,
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("\nSuccessfully merged and uploaded %s to %s\n", env.Local.TFVarsPath, env.GetFullS3Path())
+	displayUploadedVersion(newVersion)
+
+	return nil
+}
+
+func backupLocalFile(fileUtils file.Utils, env *config.Environment) (string, error) {
+	backupOpts := &file.BackupOptions{
+		BasePath:   filepath.Join(".backups", env.Name),
+		TimeFormat: "20060102150405",
+	}
+	backupPath, err := fileUtils.CreateBackup(env.Local.TFVarsPath, backupOpts)
+	if err != nil {
+		return "", fmt.Errorf("failed to create backup: %w", err)
+	}
+	fmt.Printf("Created backup: %s\n", backupPath)
+	return backupPath, nil
+}
+
+func displayChanges(changes []tfvars.Change) {
+	for _, c := range changes {
+		switch c.Type {
+		case tfvars.ChangeAdded:
+			fmt.Printf("  + %s = %s\n", c.Name, c.NewValue)
+		case tfvars.ChangeRemoved:
+			fmt.Printf("  - %s = %s\n", c.Name, c.OldValue)
+		case tfvars.ChangeModified:
+			fmt.Printf("  ~ %s = %s -> %s\n", c.Name, c.OldValue, c.NewValue)
+		}
+	}
+}
@@ -25,14 +25,21 @@ func NewPlanCmd() *cobra.Command {
 	planCmd := &cobra.Command{
 		Use:   "plan [environment]",
 		Short: "Run terraform plan for the specified environment",
-		Args:  cobra.ExactArgs(1),
+		Args:  cobra.MaximumNArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
-			opts.Environment, err = utils.GetEnvironment(args[0])
+			envName, err := utils.ResolveEnvironmentName(args)
 			if err != nil {
 				fmt.Printf("Error: %v\n", err)
 				os.Exit(1)
 			}
 
+			opts.Environment, err = utils.GetEnvironment(envName)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+			opts.VarFile = utils.ResolvePath(opts.VarFile)
+
 			if err := runPlan(cmd.Context(), utils, &opts); err != nil {
 				fmt.Printf("Error: %v\n", err)
 				os.Exit(1)
@@ -44,6 +51,7 @@ func NewPlanCmd() *cobra.Command {
 	planCmd.Flags().StringVarP(&opts.VarFile, "var-file", "v", "", "Path to terraform.tfvars file")
 	planCmd.Flags().StringVar(&opts.VersionID, "version-id", "", "Specific version ID to use (only with --remote)")
 	planCmd.Flags().StringSliceVar(&opts.Options, "options", nil, "Additional options for terraform plan")
+	planCmd.Flags().BoolVar(&opts.Reinit, "reinit", false, "Re-initialize the backend if it does not match the environment")
 
 	return planCmd
 }
@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"tfvarenv/config"
+	"tfvarenv/utils/command"
+	"tfvarenv/utils/deployment"
+)
+
+func NewProtectCmd() *cobra.Command {
+	utils, err := command.NewUtils()
+	if err != nil {
+		fmt.Printf("Error initializing command utils: %v\n", err)
+		os.Exit(1)
+	}
+
+	var (
+		lift   bool
+		reason string
+	)
+
+	protectCmd := &cobra.Command{
+		Use:   "protect [environment]",
+		Short: "Protect an environment from destroy, or lift the protection",
+		Long: `Protect an environment. Protected environments cannot be destroyed and destructive
+commands must name them explicitly. Lifting the protection requires a reason, which is
+stored in the configuration and recorded in the deployment history.
+Example: tfvarenv protect prod
+         tfvarenv protect sandbox --lift --reason "Decommissioning the sandbox account"`,
+		Args: cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if lift && reason == "" {
+				fmt.Printf("Error: --reason is required to lift the protection\n")
+				os.Exit(1)
+			}
+
+			envName, err := utils.ResolveEnvironmentName(args)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+
+			if err := runProtect(cmd.Context(), utils, envName, !lift, reason); err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+		},
+	}
+
+	protectCmd.Flags().BoolVar(&lift, "lift", false, "Lift the protection")
+	protectCmd.Flags().StringVar(&reason, "reason", "", "Reason for lifting the protection")
+
+	return protectCmd
+}
+
+func runProtect(ctx context.Context, utils command.Utils, envName string, protect bool, reason string) error {
+	env, err := utils.GetEnvironment(envName)
+	if err != nil {
+		return fmt.Errorf("failed to get environment info: %w", err)
+	}
+
+	if env.Deployment.Protected == protect {
+		if protect {
+			fmt.Printf("Environment '%s' is already protected\n", env.Name)
+		} else {
+			fmt.Printf("Environment '%s' is not protected\n", env.Name)
+		}
+		return nil
+	}
+
+	change := &config.ProtectionChange{
+		Reason: reason,
+		By:     currentIdentity(ctx, utils),
+		At:     time.Now(),
+	}
+	if protect {
+		setProtection(env, true, nil)
+	} else {
+		setProtection(env, false, change)
+	}
+
+	cfg, err := config.NewManager()
+	if err != nil {
+		return fmt.Errorf("failed to initialize config manager: %w", err)
+	}
+	if err := cfg.UpdateEnvironment(env.Name, env); err != nil {
+		return fmt.Errorf("failed to update environment: %w", err)
+	}
+
+	recordProtection(ctx, utils, env, protect, change)
+
+	if protect {
+		fmt.Printf("Environment '%s' is now protected\n", env.Name)
+	} else {
+		fmt.Printf("Protection of environment '%s' lifted by %s: %s\n", env.Name, change.By, change.Reason)
+		fmt.Printf("Re-enable it with: tfvarenv protect %s\n", env.Name)
+	}
+	return nil
+}
+
+// setProtection changes the protection of the environment, keeping the reason it was lifted
+func setProtection(env *config.Environment, protect bool, lifted *config.ProtectionChange) {
+	env.Deployment.Protected = protect
+	env.Deployment.ProtectionLifted = lifted
+}
+
+// recordProtection records a protection change in the deployment history
+func recordProtection(ctx context.Context, utils command.Utils, env *config.Environment, protect bool, change *config.ProtectionChange) {
+	record := &deployment.Record{
+		Timestamp:   change.At,
+		DeployedBy:  os.Getenv("USER"),
+		Command:     deployment.CommandUnprotect,
+		Status:      deployment.StatusSuccess,
+		Environment: env.Name,
+		Parameters: map[string]string{
+			"By":     change.By,
+			"Reason": change.Reason,
+		},
+	}
+	if protect {
+		record.Command = deployment.CommandProtect
+		delete(record.Parameters, "Reason")
+	}
+	if id, err := utils.GetIdentityResolver().Resolve(ctx, env.GetWorkingDir()); err == nil {
+		record.Identity = id
+		record.DeployedBy = id.Deployer
+	}
+
+	deploymentManager := deployment.NewManager(utils.GetAWSClient(), env)
+	if err := deploymentManager.AddRecord(ctx, record); err != nil {
+		fmt.Printf("Warning: Failed to record protection change: %v\n", err)
+	}
+}
+
+// currentIdentity returns the deployer name given by the identity resolver, falling back to the local user name
+func currentIdentity(ctx context.Context, utils command.Utils) string {
+	if id, err := utils.GetIdentityResolver().Resolve(ctx, "."); err == nil {
+		return id.Deployer
+	}
+	return os.Getenv("USER")
+}
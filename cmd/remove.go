@@ -87,6 +87,13 @@ func runRemove(ctx context.Context, utils command.Utils, envName string, force b
 		return fmt.Errorf("failed to remove environment from configuration: %w", err)
 	}
 
+	stateManager := utils.GetStateManager()
+	if current, err := stateManager.GetCurrentEnvironment(); err == nil && current == envName {
+		if err := stateManager.SetCurrentEnvironment(""); err != nil {
+			fmt.Printf("Warning: Failed to clear current environment: %v\n", err)
+		}
+	}
+
 	fmt.Printf("\nSuccessfully removed environment '%s'\n", envName)
 	return nil
 }
@@ -2,11 +2,10 @@ package cmd
 
 import (
 	"context"
-	"fmt"
-	"os"
-	"os/signal"
 
 	"github.com/spf13/cobra"
+
+	"tfvarenv/utils/interrupt"
 )
 
 func NewRootCmd() *cobra.Command {
@@ -16,19 +15,8 @@ func NewRootCmd() *cobra.Command {
 		Long: `tfvarenv simplifies the management of Terraform environments and tfvars files.
 It provides version control for tfvars files and helps manage multiple environments.`,
 		PersistentPreRun: func(cmd *cobra.Command, args []string) {
-			// Setup context with cancellation
-			ctx, cancel := context.WithCancel(context.Background())
-
-			// Handle interrupts
-			sigChan := make(chan os.Signal, 1)
-			signal.Notify(sigChan, os.Interrupt)
-			go func() {
-				<-sigChan
-				fmt.Println("\nReceived interrupt signal. Cleaning up...")
-				cancel()
-			}()
-
-			cmd.SetContext(ctx)
+			// Cancel the context on SIGINT/SIGTERM; terraform is stopped gracefully
+			cmd.SetContext(interrupt.NotifyContext(context.Background()))
 		},
 	}
 
@@ -47,5 +35,21 @@ It provides version control for tfvars files and helps manage multiple environme
 	rootCmd.AddCommand(NewDestroyCmd())
 	rootCmd.AddCommand(NewRemoveCmd())
 	rootCmd.AddCommand(NewUpdateCmd())
+	rootCmd.AddCommand(NewMergeCmd())
+	rootCmd.AddCommand(NewEditCmd())
+	rootCmd.AddCommand(NewVarCmd())
+	rootCmd.AddCommand(NewBlameCmd())
+	rootCmd.AddCommand(NewShowCmd())
+	rootCmd.AddCommand(NewChangelogCmd())
+	rootCmd.AddCommand(NewMatrixCmd())
+	rootCmd.AddCommand(NewCurrentCmd())
+	rootCmd.AddCommand(NewCleanCmd())
+	rootCmd.AddCommand(NewTfCmd())
+	rootCmd.AddCommand(NewExecCmd())
+	rootCmd.AddCommand(NewEnvCmd())
+	rootCmd.AddCommand(NewApproveCmd())
+	rootCmd.AddCommand(NewProtectCmd())
+	rootCmd.AddCommand(NewStatusCmd())
+	rootCmd.AddCommand(NewDriftCmd())
 	return rootCmd
 }
@@ -0,0 +1,226 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"tfvarenv/config"
+	"tfvarenv/utils/command"
+	"tfvarenv/utils/deployment"
+	"tfvarenv/utils/file"
+	"tfvarenv/utils/version"
+)
+
+const (
+	showLatest   = "latest"
+	showDeployed = "deployed"
+)
+
+// showOptions represents show command options
+type showOptions struct {
+	At      time.Time
+	Which   string
+	Content bool
+	Output  string
+}
+
+// timestampFormats lists the accepted formats for timestamp flags, interpreted in local time
+var timestampFormats = []string{
+	time.RFC3339,
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02",
+}
+
+func NewShowCmd() *cobra.Command {
+	utils, err := command.NewUtils()
+	if err != nil {
+		fmt.Printf("Error initializing command utils: %v\n", err)
+		os.Exit(1)
+	}
+
+	var (
+		opts showOptions
+		at   string
+	)
+
+	showCmd := &cobra.Command{
+		Use:   "show [environment]",
+		Short: "Show the state of an environment at a point in time",
+		Long: `Show the latest uploaded version and the deployed version of an environment at a point in time.
+Example: tfvarenv show prod --at "2024-05-01 14:00"
+         tfvarenv show prod --at 2024-05-01T14:00:00+09:00 --which deployed --content`,
+		Args: cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			opts.At = time.Now()
+			if at != "" {
+				t, err := parseTimestamp(at)
+				if err != nil {
+					fmt.Printf("Error: %v\n", err)
+					os.Exit(1)
+				}
+				opts.At = t
+			}
+
+			envName, err := utils.ResolveEnvironmentName(args)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+
+			opts.Output = utils.ResolvePath(opts.Output)
+			if err := runShow(cmd.Context(), utils, envName, &opts); err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+		},
+	}
+
+	showCmd.Flags().StringVar(&at, "at", "", "Point in time (YYYY-MM-DD[ HH:MM[:SS]] in local time, or RFC3339; defaults to now)")
+	showCmd.Flags().StringVar(&opts.Which, "which", showDeployed, "Version to print or download (latest, deployed)")
+	showCmd.Flags().BoolVar(&opts.Content, "content", false, "Print the tfvars content of the selected version")
+	showCmd.Flags().StringVarP(&opts.Output, "output", "o", "", "Write the tfvars content of the selected version to a file")
+
+	return showCmd
+}
+
+func runShow(ctx context.Context, utils command.Utils, envName string, opts *showOptions) error {
+	if opts.Which != showLatest && opts.Which != showDeployed {
+		return fmt.Errorf("invalid value for --which: %s (must be %s or %s)", opts.Which, showLatest, showDeployed)
+	}
+
+	env, err := utils.GetEnvironment(envName)
+	if err != nil {
+		return fmt.Errorf("environment not found: %w", err)
+	}
+
+	versionManager := version.NewManager(utils.GetAWSClient(), utils.GetFileUtils(), env)
+	deploymentManager := deployment.NewManager(utils.GetAWSClient(), env)
+
+	// Latest version uploaded at that time
+	var latestVer *version.Version
+	versions, err := versionManager.GetVersions(ctx, &version.QueryOptions{
+		Before:     opts.At,
+		SortByDate: true,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get versions: %w", err)
+	}
+	if len(versions) > 0 {
+		latestVer = &versions[0]
+	}
+
+	// Deployment active at that time
+	activeDeploy, err := deploymentManager.GetActiveDeploymentAt(ctx, opts.At)
+	if err != nil {
+		return fmt.Errorf("failed to get deployment history: %w", err)
+	}
+	var deployedVer *version.Version
+	if activeDeploy != nil {
+		deployedVer, err = versionManager.GetVersion(ctx, activeDeploy.VersionID)
+		if err != nil {
+			fmt.Printf("Warning: Deployed version %s is no longer in the version index\n", version.ShortID(activeDeploy.VersionID))
+		}
+	}
+
+	fmt.Printf("Environment '%s' at %s:\n", env.Name, opts.At.Format("2006-01-02 15:04:05 MST"))
+
+	fmt.Printf("\nLatest Uploaded Version:\n")
+	if latestVer == nil {
+		fmt.Printf("  None\n")
+	} else {
+		displayVersionSummary(latestVer)
+	}
+
+	fmt.Printf("\nDeployed Version:\n")
+	if activeDeploy == nil {
+		fmt.Printf("  None\n")
+	} else {
+		fmt.Printf("  Version ID: %s\n", version.ShortID(activeDeploy.VersionID))
+		fmt.Printf("  Deployed: %s by %s\n",
+			activeDeploy.Timestamp.Format("2006-01-02 15:04:05"),
+			activeDeploy.DeployedBy)
+		if deployedVer != nil {
+			fmt.Printf("  Uploaded: %s by %s\n",
+				deployedVer.Timestamp.Format("2006-01-02 15:04:05"),
+				deployedVer.UploadedBy)
+			if deployedVer.Description != "" {
+				fmt.Printf("  Description: %s\n", deployedVer.Description)
+			}
+		}
+		if latestVer != nil && latestVer.VersionID != activeDeploy.VersionID {
+			fmt.Printf("  Note: The latest uploaded version was not deployed at that time\n")
+		}
+	}
+
+	if !opts.Content && opts.Output == "" {
+		return nil
+	}
+
+	var selectedID string
+	switch {
+	case opts.Which == showLatest && latestVer != nil:
+		selectedID = latestVer.VersionID
+	case opts.Which == showDeployed && activeDeploy != nil:
+		selectedID = activeDeploy.VersionID
+	default:
+		return fmt.Errorf("no %s version at %s", opts.Which, opts.At.Format("2006-01-02 15:04:05"))
+	}
+
+	content, err := versionManager.GetVersionContent(ctx, selectedID)
+	if err != nil {
+		return fmt.Errorf("failed to download version: %w", err)
+	}
+
+	if opts.Output != "" {
+		return writeVersionContent(utils, env, opts.Output, content, selectedID)
+	}
+
+	fmt.Printf("\nContent of %s version %s:\n", opts.Which, version.ShortID(selectedID))
+	fmt.Print(string(content))
+	return nil
+}
+
+func displayVersionSummary(ver *version.Version) {
+	fmt.Printf("  Version ID: %s\n", version.ShortID(ver.VersionID))
+	fmt.Printf("  Uploaded: %s by %s\n",
+		ver.Timestamp.Format("2006-01-02 15:04:05"),
+		ver.UploadedBy)
+	if ver.Description != "" {
+		fmt.Printf("  Description: %s\n", ver.Description)
+	}
+}
+
+func writeVersionContent(utils command.Utils, env *config.Environment, path string, content []byte, versionID string) error {
+	if path == env.Local.TFVarsPath {
+		return fmt.Errorf("refusing to overwrite the local tfvars file; use 'tfvarenv download %s --version-id %s' instead",
+			env.Name, versionID)
+	}
+
+	writeOpts := &file.Options{
+		CreateDirs: true,
+		Overwrite:  true,
+	}
+	if err := utils.GetFileUtils().WriteFile(path, content, writeOpts); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+
+	fmt.Printf("\nWrote version %s to %s\n", version.ShortID(versionID), path)
+	return nil
+}
+
+// parseTimestamp parses a timestamp flag in one of the accepted formats
+func parseTimestamp(value string) (time.Time, error) {
+	for _, layout := range timestampFormats {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid timestamp: %s (use YYYY-MM-DD, YYYY-MM-DD HH:MM[:SS] or RFC3339)", value)
+}
@@ -0,0 +1,169 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"tfvarenv/config"
+	"tfvarenv/utils/command"
+	"tfvarenv/utils/deployment"
+	"tfvarenv/utils/version"
+)
+
+// interruptedByStatusMessage is stored as the error of records marked with --mark-interrupted
+const interruptedByStatusMessage = "marked as interrupted by 'tfvarenv status': the run did not finish"
+
+func NewStatusCmd() *cobra.Command {
+	utils, err := command.NewUtils()
+	if err != nil {
+		fmt.Printf("Error initializing command utils: %v\n", err)
+		os.Exit(1)
+	}
+
+	var (
+		staleAfter      time.Duration
+		markInterrupted bool
+	)
+
+	statusCmd := &cobra.Command{
+		Use:   "status [environment]",
+		Short: "Show the deployment status of environments",
+		Long: `Show the latest deployment and any in-progress runs of an environment (default: all environments).
+An in-progress run is reported as stale when its process is no longer running on this host,
+or when it started longer ago than --stale-after. Stale runs can be closed with --mark-interrupted.
+Example: tfvarenv status
+         tfvarenv status prod --mark-interrupted`,
+		Args: cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			var envNames []string
+			if len(args) > 0 {
+				envNames = args
+			} else {
+				names, err := utils.ListEnvironments()
+				if err != nil {
+					fmt.Printf("Error: failed to list environments: %v\n", err)
+					os.Exit(1)
+				}
+				sort.Strings(names)
+				envNames = names
+			}
+
+			if err := runStatus(cmd.Context(), utils, envNames, staleAfter, markInterrupted); err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+		},
+	}
+
+	statusCmd.Flags().DurationVar(&staleAfter, "stale-after", 2*time.Hour, "Treat in-progress runs older than this as stale")
+	statusCmd.Flags().BoolVar(&markInterrupted, "mark-interrupted", false, "Mark stale in-progress runs as interrupted")
+
+	return statusCmd
+}
+
+func runStatus(ctx context.Context, utils command.Utils, envNames []string, staleAfter time.Duration, markInterrupted bool) error {
+	if len(envNames) == 0 {
+		fmt.Println("No environments found")
+		return nil
+	}
+
+	hostname, _ := os.Hostname()
+	for i, envName := range envNames {
+		if i > 0 {
+			fmt.Println()
+		}
+
+		env, err := utils.GetEnvironment(envName)
+		if err != nil {
+			fmt.Printf("%s:\n  Error: %v\n", envName, err)
+			continue
+		}
+		if err := showEnvironmentStatus(ctx, utils, env, hostname, staleAfter, markInterrupted); err != nil {
+			fmt.Printf("  Error: %v\n", err)
+		}
+	}
+
+	return nil
+}
+
+func showEnvironmentStatus(ctx context.Context, utils command.Utils, env *config.Environment, hostname string, staleAfter time.Duration, markInterrupted bool) error {
+	fmt.Printf("%s:\n", env.Name)
+
+	deploymentManager := deployment.NewManager(utils.GetAWSClient(), env)
+	latest, err := deploymentManager.GetLatestInfo(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get deployment history: %w", err)
+	}
+	inProgress, err := deploymentManager.GetInProgress(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get in-progress runs: %w", err)
+	}
+
+	if latest != nil {
+		fmt.Printf("  Status: %s (last modified: %s)\n", latest.Status, latest.ModifiedTime.Format("2006-01-02 15:04:05"))
+		if d := latest.Deployment; d != nil {
+			fmt.Printf("  Latest Deployment: %s %s by %s (%s)\n",
+				d.Timestamp.Format("2006-01-02 15:04:05"), version.ShortID(d.VersionID), d.DeployedBy, d.Status)
+		}
+	} else {
+		fmt.Println("  Status: not deployed")
+	}
+
+	for _, record := range inProgress {
+		fmt.Printf("  In Progress: %s since %s by %s", record.Command, record.Timestamp.Format("2006-01-02 15:04:05"), record.DeployedBy)
+		if host := record.Parameters["Host"]; host != "" {
+			fmt.Printf(" on %s (pid %s)", host, record.Parameters["PID"])
+		}
+		fmt.Println()
+
+		reason := staleReason(&record, hostname, staleAfter)
+		if reason == "" {
+			continue
+		}
+		fmt.Printf("    STALE: %s\n", reason)
+
+		if !markInterrupted {
+			fmt.Printf("    Run 'tfvarenv status %s --mark-interrupted' after checking the terraform state\n", env.Name)
+			continue
+		}
+		record.Status = deployment.StatusInterrupted
+		record.ErrorMessage = interruptedByStatusMessage
+		if err := deploymentManager.UpdateRecord(ctx, &record); err != nil {
+			return fmt.Errorf("failed to mark run as interrupted: %w", err)
+		}
+		fmt.Println("    Marked as interrupted")
+	}
+
+	return nil
+}
+
+// staleReason explains why an in-progress record is considered stale, or returns "" if it may still be running
+func staleReason(record *deployment.Record, hostname string, staleAfter time.Duration) string {
+	if host := record.Parameters["Host"]; host != "" && host == hostname {
+		if pid, err := strconv.Atoi(record.Parameters["PID"]); err == nil && !processRunning(pid) {
+			return fmt.Sprintf("process %d is no longer running on this host", pid)
+		}
+	}
+	if age := time.Since(record.Timestamp); staleAfter > 0 && age > staleAfter {
+		return fmt.Sprintf("started %s ago", age.Truncate(time.Minute))
+	}
+	return ""
+}
+
+// processRunning reports whether a process with the pid exists
+func processRunning(pid int) bool {
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	err = process.Signal(syscall.Signal(0))
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
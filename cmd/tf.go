@@ -0,0 +1,257 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"tfvarenv/config"
+	"tfvarenv/utils/command"
+	"tfvarenv/utils/deployment"
+	"tfvarenv/utils/file"
+	"tfvarenv/utils/terraform"
+	"tfvarenv/utils/version"
+)
+
+// tfOptions represents tf command options
+type tfOptions struct {
+	Remote    bool
+	VersionID string
+	VarFile   string
+	Reinit    bool
+}
+
+func NewTfCmd() *cobra.Command {
+	utils, err := command.NewUtils()
+	if err != nil {
+		fmt.Printf("Error initializing command utils: %v\n", err)
+		os.Exit(1)
+	}
+
+	var opts tfOptions
+
+	tfCmd := &cobra.Command{
+		Use:   "tf [environment] -- [terraform args...]",
+		Short: "Run any terraform command in the context of an environment",
+		Long: `Run any terraform command with the environment's backend, data directory and tfvars.
+The tfvars file is passed to subcommands that accept -var-file. Commands that change
+the state (import, state rm, taint, ...) are recorded in the deployment history.
+Use 'tfvarenv apply' and 'tfvarenv destroy' for applies and destroys.
+Example: tfvarenv tf prod -- state list
+         tfvarenv tf prod --remote -- import aws_s3_bucket.logs my-logs-bucket`,
+		Args: cobra.MinimumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			envArgs, tfArgs := args, []string(nil)
+			if dash := cmd.ArgsLenAtDash(); dash >= 0 {
+				envArgs, tfArgs = args[:dash], args[dash:]
+			}
+			if len(envArgs) > 1 || len(tfArgs) == 0 {
+				fmt.Printf("Error: usage: %s\n", cmd.UseLine())
+				os.Exit(1)
+			}
+
+			envName, err := utils.ResolveEnvironmentName(envArgs)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+
+			opts.VarFile = utils.ResolvePath(opts.VarFile)
+			exitCode, err := runTf(cmd, utils, envName, envArgs, tfArgs, &opts)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				if exitCode == 0 {
+					exitCode = 1
+				}
+			}
+			if exitCode != 0 {
+				os.Exit(exitCode)
+			}
+		},
+	}
+
+	tfCmd.Flags().BoolVar(&opts.Remote, "remote", false, "Use remote tfvars file from S3")
+	tfCmd.Flags().StringVar(&opts.VersionID, "version-id", "", "Specific version ID to use (only with --remote)")
+	tfCmd.Flags().StringVarP(&opts.VarFile, "var-file", "v", "", "Path to terraform.tfvars file")
+	tfCmd.Flags().BoolVar(&opts.Reinit, "reinit", false, "Re-initialize the backend if it does not match the environment")
+
+	return tfCmd
+}
+
+// runTf runs the terraform command and returns its exit code
+func runTf(cmd *cobra.Command, utils command.Utils, envName string, envArgs, args []string, opts *tfOptions) (int, error) {
+	ctx := cmd.Context()
+	env, err := utils.GetEnvironment(envName)
+	if err != nil {
+		return 1, fmt.Errorf("environment not found: %w", err)
+	}
+
+	subcommand := terraform.Subcommand(args)
+	if err := checkTfCommand(cmd, env, envArgs, subcommand); err != nil {
+		return 1, err
+	}
+
+	versionManager := version.NewManager(utils.GetAWSClient(), utils.GetFileUtils(), env)
+	varFile, versionID, cleanup, err := prepareTfVarFile(ctx, utils, versionManager, env, opts)
+	if err != nil {
+		return 1, err
+	}
+	defer cleanup()
+
+	var record *deployment.Record
+	deploymentManager := deployment.NewManager(utils.GetAWSClient(), env)
+	if terraform.IsMutating(subcommand) {
+		record = startTfCommand(ctx, utils, deploymentManager, env, subcommand, args, versionID, varFile)
+	}
+
+	tfRunner := utils.GetTerraformRunner()
+	result, runErr := tfRunner.Exec(ctx, &terraform.ExecOptions{
+		Environment: env,
+		Args:        args,
+		VarFile:     varFile,
+		Reinit:      opts.Reinit,
+	})
+	if record != nil {
+		finishTfCommand(ctx, deploymentManager, env, record, result, runErr)
+	}
+	if result == nil {
+		// terraform was not started
+		return 1, runErr
+	}
+
+	if runErr != nil {
+		return result.ExitCode, nil
+	}
+	return 0, nil
+}
+
+// checkTfCommand refuses apply and destroy, and state changes of a protected environment
+// that was not named explicitly
+func checkTfCommand(cmd *cobra.Command, env *config.Environment, envArgs []string, subcommand string) error {
+	switch subcommand {
+	case "apply", "destroy":
+		return fmt.Errorf("use 'tfvarenv %s %s' instead", subcommand, env.Name)
+	}
+	if terraform.IsMutating(subcommand) {
+		return requireExplicitEnvironment(cmd, env, envArgs)
+	}
+	return nil
+}
+
+// prepareTfVarFile resolves the tfvars file and the version it corresponds to
+func prepareTfVarFile(ctx context.Context, utils command.Utils, versionManager version.Manager, env *config.Environment, opts *tfOptions) (string, string, func(), error) {
+	noop := func() {}
+
+	if opts.Remote {
+		var ver *version.Version
+		var err error
+		if opts.VersionID != "" {
+			ver, err = versionManager.GetVersion(ctx, opts.VersionID)
+		} else {
+			ver, err = versionManager.GetLatestVersion(ctx)
+		}
+		if err != nil {
+			return "", "", noop, fmt.Errorf("failed to get version information: %w", err)
+		}
+
+		content, err := versionManager.GetVersionContent(ctx, ver.VersionID)
+		if err != nil {
+			return "", "", noop, err
+		}
+
+		tmpDir := filepath.Join(".tmp", env.Name, "tf")
+		varFile := filepath.Join(tmpDir, env.S3.TFVarsKey)
+		writeOpts := &file.Options{
+			CreateDirs: true,
+			Overwrite:  true,
+		}
+		if err := utils.GetFileUtils().WriteFile(varFile, content, writeOpts); err != nil {
+			return "", "", noop, fmt.Errorf("failed to write temporary tfvars file: %w", err)
+		}
+		fmt.Fprintf(os.Stderr, "Using remote version %s\n", version.ShortID(ver.VersionID))
+		return varFile, ver.VersionID, func() { os.RemoveAll(tmpDir) }, nil
+	}
+
+	varFile := opts.VarFile
+	if varFile == "" {
+		varFile = env.Local.TFVarsPath
+	}
+	exists, err := utils.GetFileUtils().FileExists(varFile)
+	if err != nil {
+		return "", "", noop, fmt.Errorf("failed to check tfvars file: %w", err)
+	}
+	if !exists {
+		if opts.VarFile != "" {
+			return "", "", noop, fmt.Errorf("tfvars file not found at: %s", varFile)
+		}
+		// ローカルファイルがなくても state 操作などは実行できる
+		return "", "", noop, nil
+	}
+
+	// Identify the uploaded version with the same content, if any
+	var versionID string
+	if content, err := utils.GetFileUtils().ReadFile(varFile); err == nil {
+		hash := file.HashContent(content)
+		if versions, err := versionManager.GetVersions(ctx, nil); err == nil {
+			for _, v := range versions {
+				if v.Hash == hash {
+					versionID = v.VersionID
+					break
+				}
+			}
+		}
+	}
+	return varFile, versionID, noop, nil
+}
+
+// startTfCommand records a state-changing terraform command as in progress
+func startTfCommand(ctx context.Context, utils command.Utils, deploymentManager deployment.Manager, env *config.Environment,
+	subcommand string, args []string, versionID, varFile string) *deployment.Record {
+	record := &deployment.Record{
+		VersionID:   versionID,
+		DeployedBy:  os.Getenv("USER"),
+		Command:     subcommand,
+		Environment: env.Name,
+		Parameters: map[string]string{
+			"Args":    strings.Join(args, " "),
+			"VarFile": varFile,
+		},
+	}
+	if binary, err := utils.GetTerraformRunner().ResolveBinary(ctx, env); err == nil {
+		record.TerraformBinary = binary.Binary
+		record.TerraformVersion = binary.Version
+	}
+	if id, err := utils.GetIdentityResolver().Resolve(ctx, env.GetWorkingDir()); err == nil {
+		record.Identity = id
+		record.DeployedBy = id.Deployer
+	} else {
+		fmt.Fprintf(os.Stderr, "Warning: Failed to resolve identity: %v\n", err)
+	}
+
+	if err := deployment.Start(ctx, deploymentManager, record); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: Failed to record %s start: %v\n", subcommand, err)
+	}
+	return record
+}
+
+// finishTfCommand records the result of the terraform command
+func finishTfCommand(ctx context.Context, deploymentManager deployment.Manager, env *config.Environment,
+	record *deployment.Record, result *terraform.ExecutionResult, runErr error) {
+	if result != nil {
+		record.ExitCode = &result.ExitCode
+		runErr = nil
+		if !result.Success {
+			runErr = fmt.Errorf("exit code %d", result.ExitCode)
+		}
+	}
+
+	if err := deployment.Finish(ctx, deploymentManager, record, runErr); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: Failed to record %s: %v\n", record.Command, err)
+		return
+	}
+	fmt.Fprintf(os.Stderr, "Recorded '%s' in the deployment history of '%s'\n", record.Command, env.Name)
+}
@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"tfvarenv/config"
+)
+
+func TestCheckTfCommandProtectedEnvironment(t *testing.T) {
+	cmd := &cobra.Command{Use: "tf"}
+	env := &config.Environment{
+		Name:       "prod",
+		Deployment: config.DeploymentConfig{Protected: true},
+	}
+
+	if err := checkTfCommand(cmd, env, nil, "state rm"); err == nil {
+		t.Error("state rm on an implicit protected environment should be refused")
+	}
+	if err := checkTfCommand(cmd, env, []string{"prod"}, "state rm"); err != nil {
+		t.Errorf("state rm on an explicit protected environment should run: %v", err)
+	}
+	if err := checkTfCommand(cmd, env, nil, "state list"); err != nil {
+		t.Errorf("state list should not require an explicit environment: %v", err)
+	}
+	if err := checkTfCommand(cmd, env, []string{"prod"}, "apply"); err == nil {
+		t.Error("apply should be refused by tf")
+	}
+
+	env.Deployment.Protected = false
+	if err := checkTfCommand(cmd, env, nil, "import"); err != nil {
+		t.Errorf("import on an unprotected environment should run: %v", err)
+	}
+}
@@ -5,7 +5,9 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
@@ -25,9 +27,15 @@ func NewUpdateCmd() *cobra.Command {
 		Use:   "update [environment]",
 		Short: "Update an existing environment",
 		Long:  `Update an existing environment in tfvarenv.`,
-		Args:  cobra.ExactArgs(1),
+		Args:  cobra.MaximumNArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
-			if err := runUpdate(cmd.Context(), utils, args[0]); err != nil {
+			envName, err := utils.ResolveEnvironmentName(args)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+
+			if err := runUpdate(cmd.Context(), utils, envName); err != nil {
 				fmt.Printf("Error: %v\n", err)
 				os.Exit(1)
 			}
@@ -55,8 +63,11 @@ func runUpdate(ctx context.Context, utils command.Utils, envName string) error {
 	fmt.Printf("  S3 Bucket: %s\n", env.S3.Bucket)
 	fmt.Printf("  S3 Prefix: %s\n", env.S3.Prefix)
 	fmt.Printf("  Local Path: %s\n", env.Local.TFVarsPath)
+	fmt.Printf("  Working Directory: %s\n", env.GetWorkingDir())
 	fmt.Printf("  Auto Backup: %v\n", env.Deployment.AutoBackup)
 	fmt.Printf("  Require Approval: %v\n", env.Deployment.RequireApproval)
+	fmt.Printf("  Protected: %v\n", env.Deployment.Protected)
+	fmt.Printf("  Require Remote Approval: %v\n", env.Deployment.RequireRemoteApproval)
 	fmt.Printf("  Backend Bucket: %s\n", env.Backend.Bucket)
 	fmt.Printf("  Backend Key: %s\n", env.Backend.Key)
 	fmt.Printf("  Backend Region: %s\n", env.Backend.Region)
@@ -113,9 +124,39 @@ func runUpdate(ctx context.Context, utils command.Utils, envName string) error {
 		env.Local.TFVarsPath = strings.TrimSpace(path)
 	}
 
+	// Working Directory
+	fmt.Printf("Terraform working directory [%s]: ", env.GetWorkingDir())
+	if workingDir, _ := reader.ReadString('\n'); strings.TrimSpace(workingDir) != "" {
+		env.WorkingDir = filepath.Clean(strings.TrimSpace(workingDir))
+		if env.WorkingDir == "." {
+			env.WorkingDir = ""
+		}
+	}
+
 	// Deployment Configuration
 	env.Deployment.AutoBackup = prompt.PromptYesNo("Enable auto backup?", env.Deployment.AutoBackup)
 	env.Deployment.RequireApproval = prompt.PromptYesNo("Require deployment approval?", env.Deployment.RequireApproval)
+	wasProtected := env.Deployment.Protected
+	protect := prompt.PromptYesNo("Protect environment (destroy is refused and destructive commands require the environment name)?", wasProtected)
+	var protectionChange *config.ProtectionChange
+	if protect != wasProtected {
+		protectionChange = &config.ProtectionChange{
+			By: currentIdentity(ctx, utils),
+			At: time.Now(),
+		}
+		if !protect {
+			fmt.Print("Reason for lifting the protection: ")
+			reason, _ := reader.ReadString('\n')
+			protectionChange.Reason = strings.TrimSpace(reason)
+			if protectionChange.Reason == "" {
+				return fmt.Errorf("a reason is required to lift the protection")
+			}
+			setProtection(env, false, protectionChange)
+		} else {
+			setProtection(env, true, nil)
+		}
+	}
+	env.Deployment.RequireRemoteApproval = prompt.PromptYesNo("Require approval by another identity before apply?", env.Deployment.RequireRemoteApproval)
 
 	// Backend Configuration
 	fmt.Printf("Backend bucket name [%s]: ", env.Backend.Bucket)
@@ -151,6 +192,14 @@ func runUpdate(ctx context.Context, utils command.Utils, envName string) error {
 		if err := cfg.AddEnvironment(newEnvName, env); err != nil {
 			return fmt.Errorf("failed to add updated environment: %w", err)
 		}
+
+		// 現在の環境がリネームされた場合は追従する
+		stateManager := utils.GetStateManager()
+		if current, err := stateManager.GetCurrentEnvironment(); err == nil && current == envName {
+			if err := stateManager.SetCurrentEnvironment(newEnvName); err != nil {
+				fmt.Printf("Warning: Failed to update current environment: %v\n", err)
+			}
+		}
 	} else {
 		// Updating existing environment
 		cfg, err := config.NewManager()
@@ -162,6 +211,10 @@ func runUpdate(ctx context.Context, utils command.Utils, envName string) error {
 		}
 	}
 
+	if protectionChange != nil {
+		recordProtection(ctx, utils, env, env.Deployment.Protected, protectionChange)
+	}
+
 	fmt.Println("\nEnvironment updated successfully!")
 
 	fmt.Printf("\nFile locations:\n")
@@ -9,12 +9,32 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"tfvarenv/config"
 	"tfvarenv/utils/aws"
 	"tfvarenv/utils/command"
 	"tfvarenv/utils/file"
+	"tfvarenv/utils/tfvars"
 	"tfvarenv/utils/version"
 )
 
+// uploadOptions represents upload command options
+type uploadOptions struct {
+	Description string
+	AutoBackup  bool
+	Force       bool
+	Rebase      bool
+}
+
+// versionUploadInput represents a tfvars content to be stored as a new version
+type versionUploadInput struct {
+	Content              []byte
+	Description          string
+	ParentVersionID      string
+	MergeParentVersionID string
+	// FromLocalFile indicates that the content is the local tfvars file of the environment
+	FromLocalFile bool
+}
+
 func NewUploadCmd() *cobra.Command {
 	utils, err := command.NewUtils()
 	if err != nil {
@@ -22,30 +42,38 @@ func NewUploadCmd() *cobra.Command {
 		os.Exit(1)
 	}
 
-	var (
-		description string
-		autoBackup  bool
-	)
+	var opts uploadOptions
 
 	uploadCmd := &cobra.Command{
 		Use:   "upload [environment]",
 		Short: "Upload local tfvars file to S3",
-		Args:  cobra.ExactArgs(1),
+		Long: `Upload local tfvars file to S3.
+The upload is refused when the remote file has been updated since the local file was downloaded.
+Use --rebase to merge the remote changes into the local file, or --force to overwrite them.`,
+		Args: cobra.MaximumNArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
-			if err := runUpload(cmd.Context(), utils, args[0], description, autoBackup); err != nil {
+			envName, err := utils.ResolveEnvironmentName(args)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+
+			if err := runUpload(cmd.Context(), utils, envName, &opts); err != nil {
 				fmt.Printf("Error: %v\n", err)
 				os.Exit(1)
 			}
 		},
 	}
 
-	uploadCmd.Flags().StringVarP(&description, "description", "d", "", "Description for this version")
-	uploadCmd.Flags().BoolVar(&autoBackup, "auto-backup", true, "Create local backup before upload")
+	uploadCmd.Flags().StringVarP(&opts.Description, "description", "d", "", "Description for this version")
+	uploadCmd.Flags().BoolVar(&opts.AutoBackup, "auto-backup", true, "Create local backup before upload")
+	uploadCmd.Flags().BoolVarP(&opts.Force, "force", "f", false, "Upload even if the remote file has been updated since download")
+	uploadCmd.Flags().BoolVar(&opts.Rebase, "rebase", false, "Merge remote changes into the local file before upload")
 
 	return uploadCmd
 }
 
-func runUpload(ctx context.Context, utils command.Utils, envName, description string, autoBackup bool) error {
+func runUpload(ctx context.Context, utils command.Utils, envName string, opts *uploadOptions) error {
 	env, err := utils.GetEnvironment(envName)
 	if err != nil {
 		return fmt.Errorf("environment not found: %w", err)
@@ -71,11 +99,50 @@ func runUpload(ctx context.Context, utils command.Utils, envName, description st
 	if latestVer != nil && latestVer.Hash == fileInfo.Hash {
 		fmt.Println("Local file is identical to the latest version in S3. No upload needed.")
 		fmt.Printf("Latest version: %s (uploaded at %s)\n",
-			latestVer.VersionID[:8], latestVer.Timestamp.Format("2006-01-02 15:04:05"))
+			version.ShortID(latestVer.VersionID), latestVer.Timestamp.Format("2006-01-02 15:04:05"))
+		if err := utils.GetStateManager().SetParentVersion(env.Name, latestVer.VersionID, latestVer.Hash); err != nil {
+			fmt.Printf("Warning: Failed to update local state: %v\n", err)
+		}
 		return nil
 	}
 
-	if autoBackup {
+	content, err := fileUtils.ReadFile(env.Local.TFVarsPath)
+	if err != nil {
+		return fmt.Errorf("failed to read local file: %w", err)
+	}
+	if err := tfvars.Validate(content, env.Local.TFVarsPath); err != nil {
+		return err
+	}
+
+	envState, err := utils.GetStateManager().GetEnvironmentState(env.Name)
+	if err != nil {
+		return fmt.Errorf("failed to read local state: %w", err)
+	}
+	if envState.Merge != nil {
+		return fmt.Errorf("a merge is in progress; resolve the conflicts and run 'tfvarenv merge %s --continue'", env.Name)
+	}
+
+	parentVersionID := envState.ParentVersionID
+	if latestVer != nil && latestVer.VersionID != parentVersionID {
+		switch {
+		case opts.Rebase:
+			if parentVersionID == "" {
+				return fmt.Errorf("cannot rebase: no parent version is recorded for %s", env.Local.TFVarsPath)
+			}
+			content, err = rebaseLocalFile(ctx, versionManager, env, parentVersionID, latestVer, content)
+			if err != nil {
+				return err
+			}
+		case opts.Force:
+			fmt.Printf("Warning: Overwriting remote version %s (uploaded at %s by %s)\n",
+				version.ShortID(latestVer.VersionID), latestVer.Timestamp.Format("2006-01-02 15:04:05"), latestVer.UploadedBy)
+		default:
+			return uploadConflictError(env, parentVersionID, latestVer)
+		}
+		parentVersionID = latestVer.VersionID
+	}
+
+	if opts.AutoBackup {
 		backupOpts := &file.BackupOptions{
 			BasePath:   filepath.Join(".backups", envName),
 			TimeFormat: "20060102150405",
@@ -87,52 +154,176 @@ func runUpload(ctx context.Context, utils command.Utils, envName, description st
 		fmt.Printf("Created backup: %s\n", backupPath)
 	}
 
-	content, err := fileUtils.ReadFile(env.Local.TFVarsPath)
+	if opts.Rebase && parentVersionID != envState.ParentVersionID {
+		writeOpts := &file.Options{
+			CreateDirs: true,
+			Overwrite:  true,
+		}
+		if err := fileUtils.WriteFile(env.Local.TFVarsPath, content, writeOpts); err != nil {
+			return fmt.Errorf("failed to write rebased file: %w", err)
+		}
+		fmt.Printf("Rebased %s onto version %s\n", env.Local.TFVarsPath, version.ShortID(parentVersionID))
+
+		if file.HashContent(content) == latestVer.Hash {
+			fmt.Println("Local changes are already included in the latest version. No upload needed.")
+			if err := utils.GetStateManager().SetParentVersion(env.Name, latestVer.VersionID, latestVer.Hash); err != nil {
+				fmt.Printf("Warning: Failed to update local state: %v\n", err)
+			}
+			return nil
+		}
+	}
+
+	newVersion, err := uploadVersion(ctx, utils, env, &versionUploadInput{
+		Content:         content,
+		Description:     opts.Description,
+		ParentVersionID: parentVersionID,
+		FromLocalFile:   true,
+	})
 	if err != nil {
-		return fmt.Errorf("failed to read local file: %w", err)
+		return err
 	}
 
+	fmt.Printf("\nSuccessfully uploaded %s to %s\n", env.Local.TFVarsPath, env.GetFullS3Path())
+	displayUploadedVersion(newVersion)
+
+	return nil
+}
+
+// uploadVersion uploads the content to S3 and records it as a new version.
+// When the content comes from the local file, the new version becomes its parent.
+func uploadVersion(ctx context.Context, utils command.Utils, env *config.Environment, input *versionUploadInput) (*version.Version, error) {
+	hash := file.HashContent(input.Content)
+
 	uploadInput := &aws.UploadInput{
 		Bucket:      env.S3.Bucket,
 		Key:         env.GetS3Path(),
-		Content:     content,
-		Description: description,
+		Content:     input.Content,
+		Description: input.Description,
 		Metadata: map[string]string{
-			"Hash":        fileInfo.Hash,
-			"Description": description,
+			"Hash":        hash,
+			"Description": input.Description,
 			"UploadedBy":  os.Getenv("USER"),
 		},
 	}
 
 	uploadOutput, err := utils.GetAWSClient().UploadFile(ctx, uploadInput)
 	if err != nil {
-		return fmt.Errorf("upload failed: %w", err)
+		return nil, fmt.Errorf("upload failed: %w", err)
 	}
 
 	newVersion := &version.Version{
-		VersionID:   uploadOutput.VersionID,
-		Hash:        fileInfo.Hash,
-		Timestamp:   time.Now(),
-		Description: description,
-		UploadedBy:  os.Getenv("USER"),
-		Size:        fileInfo.Size,
+		VersionID:            uploadOutput.VersionID,
+		Hash:                 hash,
+		Timestamp:            time.Now(),
+		Description:          input.Description,
+		UploadedBy:           os.Getenv("USER"),
+		Size:                 int64(len(input.Content)),
+		ParentVersionID:      input.ParentVersionID,
+		MergeParentVersionID: input.MergeParentVersionID,
 	}
 
+	versionManager := version.NewManager(utils.GetAWSClient(), utils.GetFileUtils(), env)
 	if err := versionManager.AddVersion(ctx, newVersion); err != nil {
 		fmt.Printf("Warning: Failed to record version information: %v\n", err)
 		fmt.Println("The file was uploaded successfully, but version tracking may be incomplete.")
-		fmt.Printf("Please run 'tfvarenv upload %s' again to ensure proper version tracking.\n", envName)
-		return nil
+		fmt.Printf("Please run 'tfvarenv upload %s' again to ensure proper version tracking.\n", env.Name)
+		return newVersion, nil
 	}
 
-	fmt.Printf("\nSuccessfully uploaded %s to %s\n", env.Local.TFVarsPath, env.GetFullS3Path())
+	if input.FromLocalFile {
+		if err := utils.GetStateManager().SetParentVersion(env.Name, newVersion.VersionID, newVersion.Hash); err != nil {
+			fmt.Printf("Warning: Failed to update local state: %v\n", err)
+		}
+	}
+
+	return newVersion, nil
+}
+
+func displayUploadedVersion(newVersion *version.Version) {
 	fmt.Printf("Version Information:\n")
-	fmt.Printf("  Version ID: %s\n", newVersion.VersionID[:8])
+	fmt.Printf("  Version ID: %s\n", version.ShortID(newVersion.VersionID))
 	fmt.Printf("  Timestamp: %s\n", newVersion.Timestamp.Format("2006-01-02 15:04:05"))
 	fmt.Printf("  Size: %d bytes\n", newVersion.Size)
+	if newVersion.ParentVersionID != "" {
+		fmt.Printf("  Parent Version: %s\n", version.ShortID(newVersion.ParentVersionID))
+	}
+	if newVersion.MergeParentVersionID != "" {
+		fmt.Printf("  Merge Parent Version: %s\n", version.ShortID(newVersion.MergeParentVersionID))
+	}
 	if newVersion.Description != "" {
 		fmt.Printf("  Description: %s\n", newVersion.Description)
 	}
+}
 
-	return nil
+func uploadConflictError(env *config.Environment, parentVersionID string, latestVer *version.Version) error {
+	if parentVersionID == "" {
+		fmt.Printf("\nNo parent version is recorded for %s.\n", env.Local.TFVarsPath)
+	} else {
+		fmt.Printf("\nThe remote file has been updated since %s was downloaded:\n", env.Local.TFVarsPath)
+		fmt.Printf("  Local Parent Version: %s\n", version.ShortID(parentVersionID))
+	}
+	fmt.Printf("  Remote Latest Version: %s (uploaded at %s by %s)\n",
+		version.ShortID(latestVer.VersionID),
+		latestVer.Timestamp.Format("2006-01-02 15:04:05"),
+		latestVer.UploadedBy)
+	if latestVer.Description != "" {
+		fmt.Printf("  Description: %s\n", latestVer.Description)
+	}
+
+	fmt.Printf("\nTo resolve:\n")
+	fmt.Printf("  Merge remote changes:  tfvarenv upload %s --rebase\n", env.Name)
+	fmt.Printf("  Overwrite remote file: tfvarenv upload %s --force\n", env.Name)
+
+	return fmt.Errorf("upload conflict: remote latest version %s is not the parent of the local file", version.ShortID(latestVer.VersionID))
+}
+
+// rebaseLocalFile merges the changes between the parent version and the latest version into the local content
+func rebaseLocalFile(ctx context.Context, versionManager version.Manager, env *config.Environment, parentVersionID string, latestVer *version.Version, localContent []byte) ([]byte, error) {
+	baseContent, err := versionManager.GetVersionContent(ctx, parentVersionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download parent version: %w", err)
+	}
+	remoteContent, err := versionManager.GetVersionContent(ctx, latestVer.VersionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download latest version: %w", err)
+	}
+
+	base, err := tfvars.Parse(baseContent, "parent version "+version.ShortID(parentVersionID))
+	if err != nil {
+		return nil, err
+	}
+	remote, err := tfvars.Parse(remoteContent, "remote version "+version.ShortID(latestVer.VersionID))
+	if err != nil {
+		return nil, err
+	}
+	local, err := tfvars.Parse(localContent, env.Local.TFVarsPath)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := tfvars.Merge(base, local, remote)
+	if err != nil {
+		return nil, fmt.Errorf("failed to merge: %w", err)
+	}
+
+	if len(result.Conflicts) > 0 {
+		fmt.Printf("\nConflicting variables:\n")
+		for _, c := range result.Conflicts {
+			fmt.Printf("  %s\n", c.Name)
+			fmt.Printf("    base:   %s\n", variableValue(c.Base))
+			fmt.Printf("    local:  %s\n", variableValue(c.Local))
+			fmt.Printf("    remote: %s\n", variableValue(c.Remote))
+		}
+		return nil, fmt.Errorf("rebase failed: %d variable(s) were changed both locally and remotely; use 'tfvarenv merge %s' to resolve them",
+			len(result.Conflicts), env.Name)
+	}
+
+	return result.File.Bytes(), nil
+}
+
+func variableValue(v *tfvars.Variable) string {
+	if v == nil {
+		return "(not set)"
+	}
+	return v.Value
 }
@@ -2,15 +2,12 @@ package cmd
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"os"
 
 	"github.com/spf13/cobra"
 
-	"tfvarenv/config"
 	"tfvarenv/utils/command"
-	"tfvarenv/utils/file"
 	"tfvarenv/utils/terraform"
 	"tfvarenv/utils/version"
 )
@@ -28,7 +25,9 @@ func NewUseCmd() *cobra.Command {
 		Use:   "use [environment]",
 		Short: "Use and initialize the specified environment",
 		Long: `Use and initialize the specified environment. 
-This command switches the Terraform backend configuration and initializes the workspace.
+Each environment has its own Terraform data directory (TF_DATA_DIR), so the environment is
+only initialized the first time or when its backend configuration changed.
+The environment is remembered, so the environment argument of other commands can be omitted.
 Example: tfvarenv use production`,
 		Args: cobra.ExactArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
@@ -54,42 +53,51 @@ func runUse(ctx context.Context, utils command.Utils, envName string, force bool
 		fmt.Printf("Description: %s\n", env.Description)
 	}
 
-	// Initialize Terraform with backend configuration
-	initOpts := &terraform.InitOptions{
-		BackendConfigs: map[string]string{
-			"bucket": env.Backend.Bucket,
-			"key":    env.Backend.Key,
-			"region": env.Backend.Region,
-		},
-		Reconfigure: true,
-		ForceCopy:   force,
-	}
+	tfRunner := utils.GetTerraformRunner()
+	if force {
+		// Initialize Terraform with backend configuration
+		initOpts := &terraform.InitOptions{
+			Environment:    env,
+			BackendConfigs: terraform.BackendConfigs(&env.Backend),
+			Reconfigure:    true,
+			ForceCopy:      true,
+		}
 
-	fmt.Printf("Initializing Terraform backend...\n")
-	result, err := utils.GetTerraformRunner().Init(ctx, initOpts)
-	if err != nil {
-		return fmt.Errorf("terraform init failed: %w", err)
-	}
+		fmt.Printf("Initializing Terraform backend...\n")
+		result, err := tfRunner.Init(ctx, initOpts)
+		if err != nil {
+			return fmt.Errorf("terraform init failed: %w", err)
+		}
 
-	if !result.Success {
-		return fmt.Errorf("terraform init failed: %s", result.ErrorOutput)
+		if !result.Success {
+			return fmt.Errorf("terraform init failed: %s", result.ErrorOutput)
+		}
+	} else if err := tfRunner.EnsureBackend(ctx, env, true); err != nil {
+		return err
 	}
+
 	// Check current backend configuration
-	currentBackend, err := getCurrentBackendConfig(utils.GetFileUtils())
-	if err != nil {
-		fmt.Printf("Warning: Failed to get current backend config: %v\n", err)
-	} else {
-		if currentBackend.Bucket != env.Backend.Bucket ||
-			currentBackend.Key != env.Backend.Key ||
-			currentBackend.Region != env.Backend.Region {
+	if env.Backend.Bucket != "" {
+		currentBackend, err := tfRunner.GetBackendConfig(env)
+		if err != nil {
+			fmt.Printf("Warning: Failed to get current backend config: %v\n", err)
+		} else if !terraform.BackendMatches(currentBackend, &env.Backend) {
 			fmt.Println("\nWarning: Current backend configuration does not match the environment configuration.")
-			fmt.Println("  Please run 'terraform init' again to update the backend configuration.")
+			fmt.Printf("  Please run 'tfvarenv use %s --force' to update the backend configuration.\n", envName)
 		} else {
 			fmt.Println("\nBackend configuration is up to date.")
 		}
 	}
 
+	if err := utils.GetStateManager().SetCurrentEnvironment(envName); err != nil {
+		return fmt.Errorf("failed to save current environment: %w", err)
+	}
+
 	fmt.Printf("\nSuccessfully switched to environment '%s'\n", envName)
+	if override := os.Getenv(command.EnvironmentVariable); override != "" && override != envName {
+		fmt.Printf("Warning: %s is set to '%s' and takes precedence over this environment\n",
+			command.EnvironmentVariable, override)
+	}
 
 	// Show environment details
 	fmt.Printf("\nEnvironment Details:\n")
@@ -100,6 +108,8 @@ func runUse(ctx context.Context, utils command.Utils, envName string, force bool
 	fmt.Printf("    Key: %s\n", env.Backend.Key)
 	fmt.Printf("    Region: %s\n", env.Backend.Region)
 	fmt.Printf("  Local tfvars: %s\n", env.Local.TFVarsPath)
+	fmt.Printf("  Working Directory: %s\n", env.GetWorkingDir())
+	fmt.Printf("  Terraform Data Dir: %s\n", tfRunner.DataDir(env))
 	fmt.Printf("  S3 Path: %s\n", env.GetS3Path())
 
 	// Get latest version information if available
@@ -124,55 +134,3 @@ func runUse(ctx context.Context, utils command.Utils, envName string, force bool
 
 	return nil
 }
-
-func getCurrentBackendConfig(fileUtils file.Utils) (*config.BackendConfig, error) {
-	statePath := ".terraform/terraform.tfstate"
-	exists, err := fileUtils.FileExists(statePath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to check terraform state file: %w", err)
-	}
-	if !exists {
-		return nil, fmt.Errorf("terraform state file not found at %s", statePath)
-	}
-
-	content, err := fileUtils.ReadFile(statePath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read terraform state file: %w", err)
-	}
-
-	var stateData map[string]interface{}
-	if err := json.Unmarshal(content, &stateData); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal terraform state file: %w", err)
-	}
-
-	backend, ok := stateData["backend"].(map[string]interface{})
-	if !ok {
-		return nil, fmt.Errorf("backend information not found in terraform state file")
-	}
-
-	backendConfig, ok := backend["config"].(map[string]interface{})
-	if !ok {
-		return nil, fmt.Errorf("backend config not found in terraform state file")
-	}
-
-	bucket, ok := backendConfig["bucket"].(string)
-	if !ok {
-		return nil, fmt.Errorf("bucket not found in terraform state file")
-	}
-
-	key, ok := backendConfig["key"].(string)
-	if !ok {
-		return nil, fmt.Errorf("key not found in terraform state file")
-	}
-
-	region, ok := backendConfig["region"].(string)
-	if !ok {
-		return nil, fmt.Errorf("region not found in terraform state file")
-	}
-
-	return &config.BackendConfig{
-		Bucket: bucket,
-		Key:    key,
-		Region: region,
-	}, nil
-}
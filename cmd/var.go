@@ -0,0 +1,389 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/spf13/cobra"
+
+	"tfvarenv/config"
+	"tfvarenv/utils/command"
+	"tfvarenv/utils/deployment"
+	"tfvarenv/utils/file"
+	"tfvarenv/utils/tfvars"
+	"tfvarenv/utils/version"
+)
+
+// varOptions represents var command options
+type varOptions struct {
+	Remote      bool
+	VersionID   string
+	Description string
+	AsString    bool
+	Raw         bool
+}
+
+func NewVarCmd() *cobra.Command {
+	utils, err := command.NewUtils()
+	if err != nil {
+		fmt.Printf("Error initializing command utils: %v\n", err)
+		os.Exit(1)
+	}
+
+	varCmd := &cobra.Command{
+		Use:   "var",
+		Short: "Get, set or unset a single variable",
+		Long: `Get, set or unset a single variable in the tfvars file.
+By default the local tfvars file is edited. With --remote, the latest remote version is
+modified and uploaded as a new version directly. Comments and ordering are preserved.`,
+	}
+
+	varCmd.AddCommand(newVarGetCmd(utils))
+	varCmd.AddCommand(newVarSetCmd(utils))
+	varCmd.AddCommand(newVarUnsetCmd(utils))
+	varCmd.AddCommand(newVarHistoryCmd(utils))
+
+	return varCmd
+}
+
+func newVarGetCmd(utils command.Utils) *cobra.Command {
+	var opts varOptions
+
+	getCmd := &cobra.Command{
+		Use:   "get [environment] [name]",
+		Short: "Print the value of a variable",
+		Args:  cobra.RangeArgs(1, 2),
+		Run: func(cmd *cobra.Command, args []string) {
+			envName, rest, err := splitEnvironmentArgs(utils, args, 1)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+			if err := runVarGet(cmd.Context(), utils, envName, rest[0], &opts); err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+		},
+	}
+
+	getCmd.Flags().BoolVar(&opts.Remote, "remote", false, "Read the remote tfvars file from S3")
+	getCmd.Flags().StringVar(&opts.VersionID, "version-id", "", "Specific version ID to read (only with --remote)")
+	getCmd.Flags().BoolVar(&opts.Raw, "raw", false, "Print string values without quotes")
+
+	return getCmd
+}
+
+func newVarSetCmd(utils command.Utils) *cobra.Command {
+	var opts varOptions
+
+	setCmd := &cobra.Command{
+		Use:   "set [environment] [name] [value]",
+		Short: "Set the value of a variable",
+		Long: `Set the value of a variable.
+The value may be a number, bool, quoted string, list or map in HCL or JSON syntax.
+Any other input is stored as a string.
+Example: tfvarenv var set dev instance_count 3
+         tfvarenv var set dev tags '{"team": "infra"}'`,
+		Args: cobra.RangeArgs(2, 3),
+		Run: func(cmd *cobra.Command, args []string) {
+			envName, rest, err := splitEnvironmentArgs(utils, args, 2)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+			name := rest[0]
+			expr := tfvars.ParseValue(rest[1], opts.AsString)
+			update := func(f *tfvars.File) (bool, error) {
+				if current, ok := f.Get(name); ok && current.Value == expr {
+					return false, nil
+				}
+				return true, f.Set(name, expr)
+			}
+			if opts.Description == "" {
+				opts.Description = fmt.Sprintf("Set %s", name)
+			}
+
+			if err := runVarUpdate(cmd.Context(), utils, envName, update, &opts); err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Printf("%s = %s\n", name, expr)
+		},
+	}
+
+	setCmd.Flags().BoolVar(&opts.Remote, "remote", false, "Create a new remote version instead of editing the local file")
+	setCmd.Flags().StringVarP(&opts.Description, "description", "d", "", "Description for the new version (only with --remote)")
+	setCmd.Flags().BoolVar(&opts.AsString, "string", false, "Always treat the value as a string")
+
+	return setCmd
+}
+
+func newVarUnsetCmd(utils command.Utils) *cobra.Command {
+	var opts varOptions
+
+	unsetCmd := &cobra.Command{
+		Use:   "unset [environment] [name]",
+		Short: "Remove a variable",
+		Args:  cobra.RangeArgs(1, 2),
+		Run: func(cmd *cobra.Command, args []string) {
+			envName, rest, err := splitEnvironmentArgs(utils, args, 1)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+			name := rest[0]
+			update := func(f *tfvars.File) (bool, error) {
+				if !f.Unset(name) {
+					return false, fmt.Errorf("variable not found: %s", name)
+				}
+				return true, nil
+			}
+			if opts.Description == "" {
+				opts.Description = fmt.Sprintf("Unset %s", name)
+			}
+
+			if err := runVarUpdate(cmd.Context(), utils, envName, update, &opts); err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Printf("Removed %s\n", name)
+		},
+	}
+
+	unsetCmd.Flags().BoolVar(&opts.Remote, "remote", false, "Create a new remote version instead of editing the local file")
+	unsetCmd.Flags().StringVarP(&opts.Description, "description", "d", "", "Description for the new version (only with --remote)")
+
+	return unsetCmd
+}
+
+func newVarHistoryCmd(utils command.Utils) *cobra.Command {
+	return &cobra.Command{
+		Use:   "history [environment] [name]",
+		Short: "Show every value a variable has had",
+		Args:  cobra.RangeArgs(1, 2),
+		Run: func(cmd *cobra.Command, args []string) {
+			envName, rest, err := splitEnvironmentArgs(utils, args, 1)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+			if err := runVarHistory(cmd.Context(), utils, envName, rest[0]); err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+		},
+	}
+}
+
+func runVarGet(ctx context.Context, utils command.Utils, envName, name string, opts *varOptions) error {
+	env, err := utils.GetEnvironment(envName)
+	if err != nil {
+		return fmt.Errorf("environment not found: %w", err)
+	}
+
+	var f *tfvars.File
+	if opts.Remote {
+		f, _, err = loadRemoteTFVars(ctx, utils, env, opts.VersionID)
+	} else {
+		f, err = loadLocalTFVars(utils.GetFileUtils(), env, false)
+	}
+	if err != nil {
+		return err
+	}
+
+	v, ok := f.Get(name)
+	if !ok {
+		return fmt.Errorf("variable not found: %s", name)
+	}
+
+	if opts.Raw {
+		if s, ok := v.StringValue(); ok {
+			fmt.Println(s)
+			return nil
+		}
+	}
+	fmt.Println(v.Value)
+	return nil
+}
+
+// runVarUpdate applies the update to the local file, or to the latest remote version with --remote.
+// The update reports whether the file was changed.
+func runVarUpdate(ctx context.Context, utils command.Utils, envName string, update func(*tfvars.File) (bool, error), opts *varOptions) error {
+	env, err := utils.GetEnvironment(envName)
+	if err != nil {
+		return fmt.Errorf("environment not found: %w", err)
+	}
+
+	if !opts.Remote {
+		f, err := loadLocalTFVars(utils.GetFileUtils(), env, true)
+		if err != nil {
+			return err
+		}
+		changed, err := update(f)
+		if err != nil {
+			return err
+		}
+		if !changed {
+			fmt.Printf("%s is already up to date.\n", env.Local.TFVarsPath)
+			return nil
+		}
+
+		writeOpts := &file.Options{
+			CreateDirs: true,
+			Overwrite:  true,
+		}
+		if err := utils.GetFileUtils().WriteFile(env.Local.TFVarsPath, f.Bytes(), writeOpts); err != nil {
+			return fmt.Errorf("failed to write local file: %w", err)
+		}
+		fmt.Printf("Updated %s\n", env.Local.TFVarsPath)
+		return nil
+	}
+
+	f, latestVer, err := loadRemoteTFVars(ctx, utils, env, "")
+	if err != nil {
+		return err
+	}
+	changed, err := update(f)
+	if err != nil {
+		return err
+	}
+	if !changed {
+		fmt.Printf("Latest version %s is already up to date.\n", version.ShortID(latestVer.VersionID))
+		return nil
+	}
+
+	newVersion, err := uploadVersion(ctx, utils, env, &versionUploadInput{
+		Content:         f.Bytes(),
+		Description:     opts.Description,
+		ParentVersionID: latestVer.VersionID,
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Uploaded new version %s to %s\n", version.ShortID(newVersion.VersionID), env.GetFullS3Path())
+	return nil
+}
+
+func loadLocalTFVars(fileUtils file.Utils, env *config.Environment, allowMissing bool) (*tfvars.File, error) {
+	exists, err := fileUtils.FileExists(env.Local.TFVarsPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check local file: %w", err)
+	}
+	if !exists {
+		if allowMissing {
+			return tfvars.Parse(nil, env.Local.TFVarsPath)
+		}
+		return nil, fmt.Errorf("local file not found: %s", env.Local.TFVarsPath)
+	}
+
+	content, err := fileUtils.ReadFile(env.Local.TFVarsPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read local file: %w", err)
+	}
+	return tfvars.Parse(content, env.Local.TFVarsPath)
+}
+
+// loadRemoteTFVars loads the given version, or the latest version if versionID is empty
+func loadRemoteTFVars(ctx context.Context, utils command.Utils, env *config.Environment, versionID string) (*tfvars.File, *version.Version, error) {
+	versionManager := version.NewManager(utils.GetAWSClient(), utils.GetFileUtils(), env)
+
+	var ver *version.Version
+	var err error
+	if versionID != "" {
+		ver, err = versionManager.GetVersion(ctx, versionID)
+	} else {
+		ver, err = versionManager.GetLatestVersion(ctx)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get version information: %w", err)
+	}
+
+	content, err := versionManager.GetVersionContent(ctx, ver.VersionID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to download version: %w", err)
+	}
+
+	f, err := tfvars.Parse(content, "version "+version.ShortID(ver.VersionID))
+	if err != nil {
+		return nil, nil, err
+	}
+	return f, ver, nil
+}
+
+func runVarHistory(ctx context.Context, utils command.Utils, envName, name string) error {
+	env, err := utils.GetEnvironment(envName)
+	if err != nil {
+		return fmt.Errorf("environment not found: %w", err)
+	}
+
+	versionManager := version.NewManager(utils.GetAWSClient(), utils.GetFileUtils(), env)
+	history, err := versionManager.GetVariableHistory(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get variable history: %w", err)
+	}
+
+	changes := history.Changes[name]
+	if len(changes) == 0 {
+		return fmt.Errorf("variable not found in any version: %s", name)
+	}
+
+	// Collect successful applies by version
+	deployments := make(map[string][]deployment.Record)
+	deploymentManager := deployment.NewManager(utils.GetAWSClient(), env)
+	if records, err := deploymentManager.QueryDeployments(ctx, deployment.QueryOptions{
+		Command: deployment.CommandApply,
+		Status:  deployment.StatusSuccess,
+	}); err != nil {
+		fmt.Printf("Warning: Failed to get deployment history: %v\n", err)
+	} else {
+		for _, d := range records {
+			deployments[d.VersionID] = append(deployments[d.VersionID], d)
+		}
+	}
+
+	fmt.Printf("History of '%s' in environment '%s':\n", name, env.Name)
+	for i := len(changes) - 1; i >= 0; i-- {
+		change := changes[i]
+		currentMark := ""
+		if i == len(changes)-1 && !change.Removed {
+			currentMark = " (Current)"
+		}
+
+		fmt.Printf("\nVersion: %s%s\n", version.ShortID(change.Version.VersionID), currentMark)
+		fmt.Printf("  Uploaded: %s by %s\n",
+			change.Version.Timestamp.Format("2006-01-02 15:04:05"),
+			change.Version.UploadedBy)
+		if change.Version.Description != "" {
+			fmt.Printf("  Description: %s\n", change.Version.Description)
+		}
+		if change.Removed {
+			fmt.Printf("  Value: (removed)\n")
+			continue
+		}
+		fmt.Printf("  Value: %s\n", change.Value)
+
+		// The value was deployed if any version that kept it was deployed
+		var deployed []deployment.Record
+		for _, id := range change.VersionIDs {
+			deployed = append(deployed, deployments[id]...)
+		}
+		if len(deployed) == 0 {
+			fmt.Printf("  Deployed: No\n")
+			continue
+		}
+		sort.Slice(deployed, func(i, j int) bool {
+			return deployed[i].Timestamp.Before(deployed[j].Timestamp)
+		})
+		fmt.Printf("  Deployed:\n")
+		for _, d := range deployed {
+			fmt.Printf("    %s by %s (version %s)\n",
+				d.Timestamp.Format("2006-01-02 15:04:05"), d.DeployedBy, version.ShortID(d.VersionID))
+		}
+	}
+
+	printSkippedVersions(history)
+	return nil
+}
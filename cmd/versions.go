@@ -26,7 +26,7 @@ func NewVersionsCmd() *cobra.Command {
 	versionsCmd := &cobra.Command{
 		Use:   "versions [environment]",
 		Short: "List available versions for an environment",
-		Args:  cobra.ExactArgs(1),
+		Args:  cobra.MaximumNArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
 			// Parse since date if provided
 			if sinceStr, _ := cmd.Flags().GetString("since"); sinceStr != "" {
@@ -38,7 +38,13 @@ func NewVersionsCmd() *cobra.Command {
 				opts.Since = t
 			}
 
-			env, err := utils.GetEnvironment(args[0])
+			envName, err := utils.ResolveEnvironmentName(args)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+
+			env, err := utils.GetEnvironment(envName)
 			if err != nil {
 				fmt.Printf("Error: %v\n", err)
 				os.Exit(1)
@@ -71,7 +77,7 @@ func runVersions(ctx context.Context, utils command.Utils, env *config.Environme
 
 	// Get deployment history for status information
 	deploymentManager := deployment.NewManager(utils.GetAWSClient(), env)
-	deployments, err := deploymentManager.GetHistory(ctx)
+	deployments, err := deploymentManager.QueryDeployments(ctx, deployment.QueryOptions{Command: deployment.CommandApply})
 	if err != nil {
 		fmt.Printf("Warning: Failed to get deployment history: %v\n", err)
 	}
@@ -84,11 +90,9 @@ func runVersions(ctx context.Context, utils command.Utils, env *config.Environme
 
 	// Create deployment lookup map
 	deploymentMap := make(map[string]*deployment.Record)
-	if deployments != nil {
-		for i, d := range deployments.Deployments {
-			if _, exists := deploymentMap[d.VersionID]; !exists {
-				deploymentMap[d.VersionID] = &deployments.Deployments[i]
-			}
+	for i, d := range deployments {
+		if _, exists := deploymentMap[d.VersionID]; !exists {
+			deploymentMap[d.VersionID] = &deployments[i]
 		}
 	}
 
@@ -1,11 +1,18 @@
 package config
 
-import "fmt"
+import (
+	"fmt"
+	"time"
+
+	"tfvarenv/utils/cron"
+)
 
 // Config構造体の定義
 type Config struct {
 	Version       string                 `json:"version"`
 	DefaultRegion string                 `json:"default_region"`
+	Terraform     *TerraformConfig       `json:"terraform,omitempty"`
+	Identity      *IdentityConfig        `json:"identity,omitempty"`
 	Environments  map[string]Environment `json:"environments"`
 }
 
@@ -18,6 +25,13 @@ type Environment struct {
 	Local       LocalConfig         `json:"local"`
 	Deployment  DeploymentConfig    `json:"deployment"`
 	Backend     BackendConfig       `json:"backend"`
+	// WorkingDir is the terraform root module directory, relative to the config file
+	WorkingDir string `json:"working_dir,omitempty"`
+	// Terraform overrides the project-wide terraform settings
+	Terraform *TerraformConfig `json:"terraform,omitempty"`
+	// EnvVars are set for every terraform run. Values may reference secrets
+	// (ssm:<name>, secretsmanager:<id>[#<key>], env:<NAME>)
+	EnvVars map[string]string `json:"env_vars,omitempty"`
 }
 
 // EnvironmentS3Config構造体の定義
@@ -42,6 +56,146 @@ type LocalConfig struct {
 type DeploymentConfig struct {
 	AutoBackup      bool `json:"auto_backup"`
 	RequireApproval bool `json:"require_approval"`
+	// Protected requires destructive commands to name the environment explicitly and refuses destroy
+	Protected bool `json:"protected,omitempty"`
+	// ProtectionLifted records why the protection was removed
+	ProtectionLifted *ProtectionChange `json:"protection_lifted,omitempty"`
+	// RequireRemoteApproval requires apply to use a request approved by another identity
+	RequireRemoteApproval bool `json:"require_remote_approval,omitempty"`
+	// ApprovalTTL is how long an approval request stays valid (e.g. "24h")
+	ApprovalTTL string `json:"approval_ttl,omitempty"`
+	// Policy is evaluated before apply and destroy
+	Policy *PolicyConfig `json:"policy,omitempty"`
+	// FreezeWindows are periods in which apply and destroy are refused
+	FreezeWindows []FreezeWindow `json:"freeze_windows,omitempty"`
+	// PlanRules are checked against the plan before apply
+	PlanRules *PlanRules `json:"plan_rules,omitempty"`
+}
+
+// PlanRules limit the changes an apply may make
+type PlanRules struct {
+	// DenyDestroy are resource address patterns that must never be deleted or replaced (e.g. "aws_s3_bucket.*")
+	DenyDestroy []string `json:"deny_destroy,omitempty"`
+	// MaxChanges is the maximum number of changed resources without --force
+	MaxChanges int `json:"max_changes,omitempty"`
+}
+
+// FreezeWindow is a recurring (Schedule) or one-off (Start/End) change freeze
+type FreezeWindow struct {
+	Reason string `json:"reason"`
+	// Schedule is a cron expression. Without Duration every matching minute is frozen,
+	// with Duration the window starts at each match (e.g. "0 17 * * fri" + "64h").
+	Schedule string `json:"schedule,omitempty"`
+	Duration string `json:"duration,omitempty"`
+	// Start and End bound a one-off window ("2006-01-02" or "2006-01-02 15:04"); End is inclusive for dates
+	Start string `json:"start,omitempty"`
+	End   string `json:"end,omitempty"`
+	// Timezone is an IANA time zone name; the local time zone is used by default
+	Timezone string `json:"timezone,omitempty"`
+}
+
+// PolicyConfig defines the rules a deployment must satisfy
+type PolicyConfig struct {
+	// AllowedDeployers are IAM ARN patterns ('*' matches any characters)
+	AllowedDeployers []string `json:"allowed_deployers,omitempty"`
+	// AllowedBranches are git branch patterns of the working directory
+	AllowedBranches     []string `json:"allowed_branches,omitempty"`
+	RequireCleanGitTree bool     `json:"require_clean_git_tree,omitempty"`
+	// RequireDescription requires the applied version to have a description
+	RequireDescription bool `json:"require_description,omitempty"`
+	// TicketPattern is a regular expression the version description must match
+	TicketPattern string `json:"ticket_pattern,omitempty"`
+	// RequiredDeployments requires the same content to have been deployed elsewhere first
+	RequiredDeployments *RequiredDeployments `json:"required_deployments,omitempty"`
+	ForbidAutoApprove   bool                 `json:"forbid_auto_approve,omitempty"`
+}
+
+// RequiredDeployments requires a number of successful applies of the same content hash in another environment
+type RequiredDeployments struct {
+	Environment string `json:"environment"`
+	Count       int    `json:"count"`
+}
+
+// ProtectionChange records who lifted the protection of an environment and why
+type ProtectionChange struct {
+	Reason string    `json:"reason"`
+	By     string    `json:"by"`
+	At     time.Time `json:"at"`
+}
+
+// DefaultApprovalTTL is used when ApprovalTTL is not set
+const DefaultApprovalTTL = 24 * time.Hour
+
+// GetApprovalTTL returns the validity period of approval requests
+func (d *DeploymentConfig) GetApprovalTTL() time.Duration {
+	if ttl, err := time.ParseDuration(d.ApprovalTTL); err == nil && ttl > 0 {
+		return ttl
+	}
+	return DefaultApprovalTTL
+}
+
+// MaxFreezeDuration bounds recurring freeze windows, which are found by scanning back minute by minute
+const MaxFreezeDuration = 31 * 24 * time.Hour
+
+var freezeDateFormats = []string{
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02T15:04",
+	"2006-01-02",
+}
+
+// GetLocation returns the time zone of the freeze window
+func (w *FreezeWindow) GetLocation() (*time.Location, error) {
+	if w.Timezone == "" {
+		return time.Local, nil
+	}
+	return time.LoadLocation(w.Timezone)
+}
+
+// GetSchedule parses the cron expression of a recurring freeze window
+func (w *FreezeWindow) GetSchedule() (*cron.Schedule, error) {
+	return cron.Parse(w.Schedule)
+}
+
+// GetDuration returns the length of each recurrence, or 0 when every matching minute is frozen
+func (w *FreezeWindow) GetDuration() (time.Duration, error) {
+	if w.Duration == "" {
+		return 0, nil
+	}
+	duration, err := time.ParseDuration(w.Duration)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration: %w", err)
+	}
+	if duration <= 0 || duration > MaxFreezeDuration {
+		return 0, fmt.Errorf("duration must be between 1m and %s", MaxFreezeDuration)
+	}
+	return duration, nil
+}
+
+// GetRange returns the bounds of a one-off freeze window in loc
+func (w *FreezeWindow) GetRange(loc *time.Location) (time.Time, time.Time, error) {
+	start, _, err := parseFreezeDate(w.Start, loc)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	end, dateOnly, err := parseFreezeDate(w.End, loc)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	if dateOnly {
+		// 日付のみの終了日はその日を含む
+		end = end.AddDate(0, 0, 1)
+	}
+	return start, end, nil
+}
+
+func parseFreezeDate(value string, loc *time.Location) (time.Time, bool, error) {
+	for _, format := range freezeDateFormats {
+		if t, err := time.ParseInLocation(format, value, loc); err == nil {
+			return t, format == "2006-01-02", nil
+		}
+	}
+	return time.Time{}, false, fmt.Errorf("invalid date: %q", value)
 }
 
 // BackendConfig構造体の定義
@@ -51,6 +205,32 @@ type BackendConfig struct {
 	Region string `json:"region"`
 }
 
+// IdentityConfig selects how the deployer recorded in the deployment history is resolved
+type IdentityConfig struct {
+	// Resolver is "sts" (caller ARN, default), "ci" (CI actor), "env", "command" or "user"
+	Resolver string `json:"resolver,omitempty"`
+	// EnvVar is read by the "env" resolver (default: TFVARENV_DEPLOYER)
+	EnvVar string `json:"env_var,omitempty"`
+	// Command is run by the "command" resolver; its output is the deployer
+	Command []string `json:"command,omitempty"`
+}
+
+// TerraformConfig構造体の定義
+type TerraformConfig struct {
+	// Binary is "terraform", "tofu", or a path to the executable
+	Binary string `json:"binary,omitempty"`
+	// RequiredVersion is a version constraint such as "~> 1.10.0"
+	RequiredVersion string `json:"required_version,omitempty"`
+}
+
+// GetWorkingDir returns the terraform root module directory of the environment
+func (e *Environment) GetWorkingDir() string {
+	if e.WorkingDir == "" {
+		return "."
+	}
+	return e.WorkingDir
+}
+
 // GetS3Path returns the full path for the tfvars file (without s3:// prefix)
 func (e *Environment) GetS3Path() string {
 	return fmt.Sprintf("%s/%s", e.S3.Prefix, e.S3.TFVarsKey)
@@ -66,7 +246,18 @@ func (e *Environment) GetVersionMetadataKey() string {
 	return fmt.Sprintf("%s/.%s.versions.json", e.S3.Prefix, e.S3.TFVarsKey)
 }
 
-// GetDeploymentHistoryKey returns the S3 key for deployment history
+// GetApprovalPrefix returns the S3 prefix of approval requests
+func (e *Environment) GetApprovalPrefix() string {
+	return fmt.Sprintf("%s/.approvals/%s/", e.S3.Prefix, e.Name)
+}
+
+// GetDeploymentHistoryKey returns the S3 key of the single-file deployment history
+// used before records were stored individually
 func (e *Environment) GetDeploymentHistoryKey() string {
 	return fmt.Sprintf("%s/.%s.deployments.json", e.S3.Prefix, e.Name)
 }
+
+// GetDeploymentPrefix returns the S3 prefix of the deployment records and their head pointer
+func (e *Environment) GetDeploymentPrefix() string {
+	return fmt.Sprintf("%s/.deployments/%s/", e.S3.Prefix, e.Name)
+}
@@ -22,6 +22,9 @@ type Manager interface {
 	UpdateEnvironment(name string, env *Environment) error
 	ListEnvironments() ([]string, error)
 	GetDefaultRegion() (string, error)
+	GetRootDir() string
+	GetTerraformConfig() *TerraformConfig
+	GetIdentityConfig() *IdentityConfig
 	Save() error
 }
 
@@ -39,7 +42,7 @@ func NewManager() (Manager, error) {
 	}
 
 	m := &manager{
-		configPath: filepath.Join(cwd, configFileName),
+		configPath: filepath.Join(findRootDir(cwd), configFileName),
 		config: Config{
 			Version:       defaultVersion,
 			DefaultRegion: "ap-northeast-1",
@@ -54,6 +57,50 @@ func NewManager() (Manager, error) {
 	return m, nil
 }
 
+// findRootDir walks up from dir to the directory containing the config file.
+// It returns dir itself if no config file is found.
+func findRootDir(dir string) string {
+	for current := dir; ; {
+		if _, err := os.Stat(filepath.Join(current, configFileName)); err == nil {
+			return current
+		}
+		parent := filepath.Dir(current)
+		if parent == current {
+			return dir
+		}
+		current = parent
+	}
+}
+
+// GetTerraformConfig returns the project-wide terraform settings
+func (m *manager) GetTerraformConfig() *TerraformConfig {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.config.Terraform == nil {
+		return &TerraformConfig{}
+	}
+	tfConfig := *m.config.Terraform
+	return &tfConfig
+}
+
+// GetIdentityConfig returns the project-wide identity settings
+func (m *manager) GetIdentityConfig() *IdentityConfig {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.config.Identity == nil {
+		return &IdentityConfig{}
+	}
+	identityConfig := *m.config.Identity
+	return &identityConfig
+}
+
+// GetRootDir returns the directory containing the config file
+func (m *manager) GetRootDir() string {
+	return filepath.Dir(m.configPath)
+}
+
 func (m *manager) load() error {
 	data, err := os.ReadFile(m.configPath)
 	if err != nil {
@@ -64,6 +111,10 @@ func (m *manager) load() error {
 		return fmt.Errorf("failed to unmarshal config: %w", err)
 	}
 
+	if err := validateConfig(&m.config); err != nil {
+		return fmt.Errorf("invalid config %s: %w", m.configPath, err)
+	}
+
 	return nil
 }
 
@@ -106,6 +157,9 @@ func (m *manager) AddEnvironment(name string, env *Environment) error {
 	if _, exists := m.config.Environments[name]; exists {
 		return fmt.Errorf("environment '%s' already exists", name)
 	}
+	if err := validateRequiredDeployments(name, &env.Deployment, m.config.Environments); err != nil {
+		return fmt.Errorf("invalid environment configuration: %w", err)
+	}
 
 	m.config.Environments[name] = *env
 	return m.save()
@@ -119,6 +173,12 @@ func (m *manager) RemoveEnvironment(name string) error {
 	if _, exists := m.config.Environments[name]; !exists {
 		return fmt.Errorf("environment '%s' does not exist", name)
 	}
+	for other, env := range m.config.Environments {
+		if policy := env.Deployment.Policy; other != name && policy != nil &&
+			policy.RequiredDeployments != nil && policy.RequiredDeployments.Environment == name {
+			return fmt.Errorf("environment '%s' is required by the deployment policy of '%s'", name, other)
+		}
+	}
 
 	delete(m.config.Environments, name)
 	return m.save()
@@ -136,6 +196,9 @@ func (m *manager) UpdateEnvironment(name string, env *Environment) error {
 	if err := validateEnvironment(env); err != nil {
 		return fmt.Errorf("invalid environment configuration: %w", err)
 	}
+	if err := validateRequiredDeployments(name, &env.Deployment, m.config.Environments); err != nil {
+		return fmt.Errorf("invalid environment configuration: %w", err)
+	}
 
 	m.config.Environments[name] = *env
 	return m.save()
@@ -5,8 +5,22 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"sort"
+	"time"
 )
 
+var envVarNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// identityResolvers are the resolver names of tfvarenv/utils/identity
+var identityResolvers = map[string]bool{
+	"sts":     true,
+	"ci":      true,
+	"env":     true,
+	"command": true,
+	"user":    true,
+}
+
 // validateEnvironment performs validation of environment configuration
 func validateEnvironment(env *Environment) error {
 	if env.Name == "" {
@@ -25,9 +39,79 @@ func validateEnvironment(env *Environment) error {
 		return err
 	}
 
+	if err := validateWorkingDir(env.WorkingDir); err != nil {
+		return err
+	}
+
+	if err := validateEnvVars(env.EnvVars); err != nil {
+		return err
+	}
+
 	return validateDeploymentConfig(&env.Deployment)
 }
 
+// validateConfig performs the checks that need the whole configuration, when it is loaded
+func validateConfig(cfg *Config) error {
+	if cfg.Identity != nil {
+		if err := validateIdentityConfig(cfg.Identity); err != nil {
+			return err
+		}
+	}
+
+	names := make([]string, 0, len(cfg.Environments))
+	for name := range cfg.Environments {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		env := cfg.Environments[name]
+		for _, window := range env.Deployment.FreezeWindows {
+			if err := validateFreezeWindow(&window); err != nil {
+				return fmt.Errorf("environment %s: %w", name, err)
+			}
+		}
+		if env.Deployment.Policy == nil {
+			continue
+		}
+		if err := validatePolicyConfig(env.Deployment.Policy); err != nil {
+			return fmt.Errorf("environment %s: %w", name, err)
+		}
+		if err := validateRequiredDeployments(name, &env.Deployment, cfg.Environments); err != nil {
+			return fmt.Errorf("environment %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func validateIdentityConfig(identity *IdentityConfig) error {
+	if identity.Resolver != "" && !identityResolvers[identity.Resolver] {
+		return fmt.Errorf("unknown identity resolver: %q (must be sts, ci, env, command or user)", identity.Resolver)
+	}
+	if identity.Resolver == "command" && len(identity.Command) == 0 {
+		return errors.New("identity resolver \"command\" needs a command")
+	}
+	if identity.EnvVar != "" && !envVarNamePattern.MatchString(identity.EnvVar) {
+		return fmt.Errorf("invalid identity environment variable name: %q", identity.EnvVar)
+	}
+	return nil
+}
+
+// validateRequiredDeployments checks that required deployments refer to another known environment
+func validateRequiredDeployments(name string, deploy *DeploymentConfig, envs map[string]Environment) error {
+	if deploy.Policy == nil || deploy.Policy.RequiredDeployments == nil {
+		return nil
+	}
+	other := deploy.Policy.RequiredDeployments.Environment
+	if other == name {
+		return errors.New("required deployments cannot refer to the environment itself")
+	}
+	if _, exists := envs[other]; !exists {
+		return fmt.Errorf("required deployments refer to unknown environment: %s", other)
+	}
+	return nil
+}
+
 func validateS3Config(s3 *EnvironmentS3Config) error {
 	if s3.Bucket == "" {
 		return errors.New("S3 bucket is required")
@@ -69,7 +153,109 @@ func validateLocalConfig(local *LocalConfig) error {
 	return nil
 }
 
+func validateWorkingDir(dir string) error {
+	if dir == "" {
+		return nil
+	}
+	if filepath.IsAbs(dir) {
+		return fmt.Errorf("working directory must be relative to the config file: %s", dir)
+	}
+
+	info, err := os.Stat(dir)
+	if err != nil {
+		return fmt.Errorf("working directory not found: %s", dir)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("working directory is not a directory: %s", dir)
+	}
+
+	return nil
+}
+
+func validateEnvVars(vars map[string]string) error {
+	for name := range vars {
+		if !envVarNamePattern.MatchString(name) {
+			return fmt.Errorf("invalid environment variable name: %q", name)
+		}
+		if name == "TF_DATA_DIR" {
+			return errors.New("TF_DATA_DIR is managed by tfvarenv and cannot be set in env_vars")
+		}
+	}
+	return nil
+}
+
 func validateDeploymentConfig(deploy *DeploymentConfig) error {
-	// Currently no validation rules for deployment config
+	if deploy.ApprovalTTL != "" {
+		ttl, err := time.ParseDuration(deploy.ApprovalTTL)
+		if err != nil || ttl <= 0 {
+			return fmt.Errorf("invalid approval TTL: %q", deploy.ApprovalTTL)
+		}
+	}
+	for _, window := range deploy.FreezeWindows {
+		if err := validateFreezeWindow(&window); err != nil {
+			return err
+		}
+	}
+	if deploy.Policy != nil {
+		return validatePolicyConfig(deploy.Policy)
+	}
+	return nil
+}
+
+func validateFreezeWindow(window *FreezeWindow) error {
+	if window.Reason == "" {
+		return errors.New("freeze window reason is required")
+	}
+	if (window.Schedule == "") == (window.Start == "") {
+		return fmt.Errorf("freeze window %q needs either a schedule or a start", window.Reason)
+	}
+	loc, err := window.GetLocation()
+	if err != nil {
+		return fmt.Errorf("invalid time zone in freeze window %q: %w", window.Reason, err)
+	}
+
+	if window.Schedule != "" {
+		if window.End != "" {
+			return fmt.Errorf("freeze window %q cannot have both a schedule and an end", window.Reason)
+		}
+		if _, err := window.GetSchedule(); err != nil {
+			return fmt.Errorf("invalid schedule in freeze window %q: %w", window.Reason, err)
+		}
+		if _, err := window.GetDuration(); err != nil {
+			return fmt.Errorf("invalid freeze window %q: %w", window.Reason, err)
+		}
+		return nil
+	}
+
+	if window.Duration != "" {
+		return fmt.Errorf("freeze window %q has a duration but no schedule", window.Reason)
+	}
+	if window.End == "" {
+		return fmt.Errorf("freeze window %q needs an end", window.Reason)
+	}
+	start, end, err := window.GetRange(loc)
+	if err != nil {
+		return fmt.Errorf("invalid freeze window %q: %w", window.Reason, err)
+	}
+	if !start.Before(end) {
+		return fmt.Errorf("freeze window %q ends before it starts", window.Reason)
+	}
+	return nil
+}
+
+func validatePolicyConfig(policy *PolicyConfig) error {
+	if policy.TicketPattern != "" {
+		if _, err := regexp.Compile(policy.TicketPattern); err != nil {
+			return fmt.Errorf("invalid ticket pattern: %w", err)
+		}
+	}
+	if req := policy.RequiredDeployments; req != nil {
+		if req.Environment == "" {
+			return errors.New("required deployments need an environment")
+		}
+		if req.Count < 1 {
+			return errors.New("required deployments count must be at least 1")
+		}
+	}
 	return nil
 }
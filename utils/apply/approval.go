@@ -0,0 +1,67 @@
+package apply
+
+import (
+	"context"
+	"fmt"
+
+	"tfvarenv/utils/approval"
+	"tfvarenv/utils/plan"
+	"tfvarenv/utils/terraform"
+	"tfvarenv/utils/version"
+)
+
+// loadApproval validates the approval request and pins the approved version
+func (m *Manager) loadApproval(ctx context.Context, opts *Options) (*approval.Request, error) {
+	approvalManager := approval.NewManager(m.awsClient, opts.Environment)
+	req, err := approvalManager.Validate(ctx, opts.ApprovalID)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.VersionID != "" && opts.VersionID != req.VersionID {
+		return nil, fmt.Errorf("approval request %s is for version %s, not %s", req.ID, req.VersionID, opts.VersionID)
+	}
+	opts.Remote = true
+	opts.VersionID = req.VersionID
+
+	fmt.Printf("Using approval request %s (approved by %s at %s)\n",
+		req.ID, req.ReviewedBy, req.ReviewedAt.Format("2006-01-02 15:04:05"))
+	return req, nil
+}
+
+// requestApproval plans the version and stores a pending approval request
+func (m *Manager) requestApproval(ctx context.Context, opts *Options, versionInfo *VersionInfo) error {
+	fmt.Printf("\nPlanning version %s for the approval request...\n", version.ShortID(versionInfo.Version.VersionID))
+	result, err := m.tfRunner.Plan(ctx, &terraform.PlanOptions{
+		Environment: opts.Environment,
+		Remote:      true,
+		VersionID:   versionInfo.Version.VersionID,
+		NoColor:     true,
+		Reinit:      opts.Reinit,
+	})
+	if err != nil {
+		return fmt.Errorf("terraform plan failed: %w", err)
+	}
+
+	summary, err := plan.ParseSummary(result.Output)
+	if err != nil {
+		return err
+	}
+
+	approvalManager := approval.NewManager(m.awsClient, opts.Environment)
+	req, err := approvalManager.Create(ctx, versionInfo.Version.VersionID, summary)
+	if err != nil {
+		return fmt.Errorf("failed to create approval request: %w", err)
+	}
+
+	fmt.Printf("\nApproval requested:\n")
+	fmt.Printf("  Request ID: %s\n", req.ID)
+	fmt.Printf("  Version: %s\n", version.ShortID(req.VersionID))
+	fmt.Printf("  Plan: %s\n", summary)
+	fmt.Printf("  Requested By: %s\n", req.RequestedBy)
+	fmt.Printf("  Expires: %s\n", req.ExpiresAt.Format("2006-01-02 15:04:05"))
+	fmt.Printf("\nNext steps:\n")
+	fmt.Printf("  Approve (another identity): tfvarenv approve %s %s\n", opts.Environment.Name, req.ID)
+	fmt.Printf("  Apply after approval:       tfvarenv apply %s --approval-id %s\n", opts.Environment.Name, req.ID)
+	return nil
+}
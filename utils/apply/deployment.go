@@ -3,42 +3,58 @@ package apply
 import (
 	"context"
 	"fmt"
-	"os"
-	"time"
 
 	"tfvarenv/utils/deployment"
+	"tfvarenv/utils/version"
 )
 
-func (m *Manager) recordDeployment(ctx context.Context, opts *Options, versionInfo *VersionInfo, status string, err error) {
-	deploymentManager := deployment.NewManager(m.awsClient, opts.Environment)
-	record := &deployment.Record{
-		Timestamp:   time.Now(),
-		VersionID:   versionInfo.Version.VersionID,
-		DeployedBy:  os.Getenv("USER"),
-		Command:     "apply",
-		Status:      status,
-		Environment: opts.Environment.Name,
-		Parameters: map[string]string{
-			"AutoApprove": fmt.Sprintf("%v", opts.AutoApprove),
-			"Remote":      fmt.Sprintf("%v", opts.Remote),
-			"VarFile":     versionInfo.SourceFile,
-		},
+// startDeployment completes the record (break-glass and plan check are set by the caller)
+// and saves it as in progress before terraform starts
+func (m *Manager) startDeployment(ctx context.Context, opts *Options, versionInfo *VersionInfo, record *deployment.Record) {
+	record.VersionID = versionInfo.Version.VersionID
+	record.DeployedBy = record.Identity.Deployer
+	record.Command = deployment.CommandApply
+	record.Environment = opts.Environment.Name
+	record.Parameters = map[string]string{
+		"AutoApprove": fmt.Sprintf("%v", opts.AutoApprove),
+		"Remote":      fmt.Sprintf("%v", opts.Remote),
+		"VarFile":     versionInfo.SourceFile,
+	}
+	if opts.ApprovalID != "" {
+		record.Parameters["ApprovalID"] = opts.ApprovalID
+	}
+	if binary, err := m.tfRunner.ResolveBinary(ctx, opts.Environment); err == nil {
+		record.TerraformBinary = binary.Binary
+		record.TerraformVersion = binary.Version
 	}
 
-	if err != nil {
-		record.ErrorMessage = err.Error()
+	deploymentManager := deployment.NewManager(m.awsClient, opts.Environment)
+	if err := deployment.Start(ctx, deploymentManager, record); err != nil {
+		fmt.Printf("Warning: Failed to record deployment start: %v\n", err)
 	}
+}
 
-	if recordErr := deploymentManager.AddRecord(ctx, record); recordErr != nil {
+// recordDeployment completes the in-progress record with the result of terraform
+func (m *Manager) recordDeployment(ctx context.Context, opts *Options, versionInfo *VersionInfo, record *deployment.Record, err error) {
+	deploymentManager := deployment.NewManager(m.awsClient, opts.Environment)
+	if recordErr := deployment.Finish(ctx, deploymentManager, record, err); recordErr != nil {
 		fmt.Printf("Warning: Failed to record deployment: %v\n", recordErr)
 		return
 	}
 
 	fmt.Printf("\nDeployment recorded:\n")
-	fmt.Printf("  Version: %s\n", versionInfo.Version.VersionID[:8])
+	fmt.Printf("  Version: %s\n", version.ShortID(versionInfo.Version.VersionID))
 	fmt.Printf("  Time: %s\n", record.Timestamp.Format("2006-01-02 15:04:05"))
 	fmt.Printf("  By: %s\n", record.DeployedBy)
-	if err != nil {
+	if record.PlanCheck != nil {
+		fmt.Printf("  Plan: %s\n", record.PlanCheck.Summary)
+	}
+	if record.BreakGlass != nil {
+		fmt.Printf("  Break-Glass: %s\n", record.BreakGlass.Reason)
+	}
+	if record.Status == deployment.StatusInterrupted {
+		fmt.Printf("  Status: Interrupted\n")
+	} else if err != nil {
 		fmt.Printf("  Status: Failed (%s)\n", err.Error())
 	}
 }
@@ -2,50 +2,130 @@ package apply
 
 import (
 	"context"
+	"fmt"
+	"os"
+	"time"
 
+	"tfvarenv/utils/approval"
 	"tfvarenv/utils/aws"
+	"tfvarenv/utils/deployment"
 	"tfvarenv/utils/file"
+	"tfvarenv/utils/freeze"
+	"tfvarenv/utils/identity"
+	"tfvarenv/utils/policy"
 	"tfvarenv/utils/terraform"
 )
 
 // Manager handles the apply command execution
 type Manager struct {
-	awsClient aws.Client
-	fileUtils file.Utils
-	tfRunner  terraform.Runner
+	awsClient  aws.Client
+	fileUtils  file.Utils
+	tfRunner   terraform.Runner
+	idResolver identity.Resolver
+	lookupEnv  policy.EnvironmentLookup
 }
 
 // NewManager creates a new apply manager
-func NewManager(awsClient aws.Client, fileUtils file.Utils, tfRunner terraform.Runner) *Manager {
+func NewManager(awsClient aws.Client, fileUtils file.Utils, tfRunner terraform.Runner, idResolver identity.Resolver, lookupEnv policy.EnvironmentLookup) *Manager {
 	return &Manager{
-		awsClient: awsClient,
-		fileUtils: fileUtils,
-		tfRunner:  tfRunner,
+		awsClient:  awsClient,
+		fileUtils:  fileUtils,
+		tfRunner:   tfRunner,
+		idResolver: idResolver,
+		lookupEnv:  lookupEnv,
 	}
 }
 
 // Execute runs the apply command with given options
 func (m *Manager) Execute(ctx context.Context, opts *Options) error {
+	// Check the terraform version and the initialized backend before uploading anything
+	if _, err := m.tfRunner.ResolveBinary(ctx, opts.Environment); err != nil {
+		return err
+	}
+	if err := m.tfRunner.EnsureBackend(ctx, opts.Environment, opts.Reinit); err != nil {
+		return err
+	}
+	id, err := m.idResolver.Resolve(ctx, opts.Environment.GetWorkingDir())
+	if err != nil {
+		return fmt.Errorf("failed to resolve identity: %w", err)
+	}
+
+	// An approved request pins the version to apply
+	var approved *approval.Request
+	if opts.RequestApproval && opts.ApprovalID != "" {
+		return fmt.Errorf("--request-approval and --approval-id cannot be used together")
+	}
+	if opts.ApprovalID != "" {
+		var err error
+		if approved, err = m.loadApproval(ctx, opts); err != nil {
+			return err
+		}
+	} else if opts.Environment.Deployment.RequireRemoteApproval && !opts.RequestApproval {
+		return fmt.Errorf("environment %s requires an approved request; run 'tfvarenv apply %s --request-approval' and apply with --approval-id",
+			opts.Environment.Name, opts.Environment.Name)
+	}
+
 	// Get version information
 	versionInfo, err := m.getVersionInfo(ctx, opts)
 	if err != nil {
 		return err
 	}
 
-	// Get deployment approval if required
-	if err := m.checkApproval(opts); err != nil {
+	if opts.RequestApproval {
+		return m.requestApproval(ctx, opts, versionInfo)
+	}
+
+	policyManager := policy.NewManager(m.awsClient, m.fileUtils, m.lookupEnv)
+	if err := policyManager.Enforce(ctx, &policy.Input{
+		Environment: opts.Environment,
+		Command:     deployment.CommandApply,
+		Version:     versionInfo.Version,
+		AutoApprove: opts.AutoApprove,
+	}, opts.Explain); err != nil {
+		return err
+	}
+
+	breakGlass, err := freeze.Enforce(opts.Environment, opts.BreakGlass, time.Now())
+	if err != nil {
+		return err
+	}
+	record := &deployment.Record{BreakGlass: breakGlass, Identity: id}
+
+	// Plan first and check the changes
+	defer os.RemoveAll(tmpDir(opts))
+	saved, err := m.runPlan(ctx, opts, versionInfo)
+	if err != nil {
+		return err
+	}
+	if err := m.checkPlan(opts, saved); err != nil {
+		return err
+	}
+	record.PlanCheck = saved.Check
+	record.Changes = saved.Check.Summary
+
+	// The saved plan is applied without terraform's own prompt
+	if err := m.checkApproval(opts, approved); err != nil {
 		return err
 	}
 
 	// Run terraform apply
-	result, err := m.runTerraformApply(ctx, opts, versionInfo)
+	m.startDeployment(ctx, opts, versionInfo, record)
+	result, err := m.runTerraformApply(ctx, opts, saved)
+	if result != nil {
+		record.ExitCode = &result.ExitCode
+	}
 	if err != nil {
-		m.recordDeployment(ctx, opts, versionInfo, "failed", err)
+		m.recordDeployment(ctx, opts, versionInfo, record, err)
 		return err
 	}
 
 	// Record successful deployment
-	m.recordDeployment(ctx, opts, versionInfo, "success", nil)
+	m.recordDeployment(ctx, opts, versionInfo, record, nil)
+	if approved != nil {
+		if err := approval.NewManager(m.awsClient, opts.Environment).MarkApplied(ctx, approved); err != nil {
+			fmt.Printf("Warning: Failed to mark approval request as applied: %v\n", err)
+		}
+	}
 
 	// Display result
 	m.displayResult(result, versionInfo)
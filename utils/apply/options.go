@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strings"
 	"tfvarenv/config"
+	"tfvarenv/utils/approval"
 )
 
 // Options represents apply command options
@@ -14,10 +15,29 @@ type Options struct {
 	VarFile       string
 	AutoApprove   bool
 	TerraformOpts []string
+	Reinit        bool
+	// RequestApproval stores an approval request instead of applying
+	RequestApproval bool
+	// ApprovalID applies the version of an approved request
+	ApprovalID string
+	// Explain shows the result of every deployment policy rule
+	Explain bool
+	// BreakGlass is the reason for applying inside a freeze window
+	BreakGlass string
+	// Force overrides plan rules such as the maximum number of changes
+	Force bool
+	// ConfirmDestroy lists deleted or replaced addresses confirmed in advance
+	ConfirmDestroy []string
 }
 
-func (m *Manager) checkApproval(opts *Options) error {
-	if opts.Environment.Deployment.RequireApproval && !opts.AutoApprove {
+// checkApproval prompts before applying unless --auto-approve is given, since terraform
+// does not prompt for a saved plan. An approved remote request has already been reviewed
+// and is not prompted again.
+func (m *Manager) checkApproval(opts *Options, approved *approval.Request) error {
+	if approved != nil {
+		return nil
+	}
+	if !opts.AutoApprove {
 		if !promptYesNo(fmt.Sprintf("\nDo you want to proceed with applying to %s environment?",
 			opts.Environment.Name), false) {
 			return fmt.Errorf("deployment cancelled by user")
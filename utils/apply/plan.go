@@ -0,0 +1,153 @@
+package apply
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"tfvarenv/utils/aws"
+	"tfvarenv/utils/file"
+	"tfvarenv/utils/plan"
+	"tfvarenv/utils/terraform"
+)
+
+// savedPlan is the plan an apply runs
+type savedPlan struct {
+	File  string
+	Plan  *plan.Plan
+	Check *plan.CheckResult
+}
+
+// tmpDir holds the tfvars file and the saved plan of an apply
+func tmpDir(opts *Options) string {
+	return filepath.Join(".tmp", opts.Environment.Name)
+}
+
+// prepareVarFile returns the tfvars file of the version, downloading it in remote mode
+func (m *Manager) prepareVarFile(ctx context.Context, opts *Options, versionInfo *VersionInfo) (string, error) {
+	if !opts.Remote {
+		// ローカルモードではファイルパスをそのまま使用
+		return versionInfo.SourceFile, nil
+	}
+
+	// Download tfvars file
+	input := &aws.DownloadInput{
+		Bucket:    opts.Environment.S3.Bucket,
+		Key:       opts.Environment.GetS3Path(),
+		VersionID: versionInfo.Version.VersionID,
+	}
+	output, err := m.awsClient.DownloadFile(ctx, input)
+	if err != nil {
+		return "", fmt.Errorf("failed to download tfvars: %w", err)
+	}
+
+	varFile := filepath.Join(tmpDir(opts), "terraform.tfvars")
+	if err := m.fileUtils.WriteFile(varFile, output.Content, &file.Options{
+		CreateDirs: true,
+		Overwrite:  true,
+	}); err != nil {
+		return "", fmt.Errorf("failed to write temporary tfvars file: %w", err)
+	}
+	return varFile, nil
+}
+
+// runPlan saves a plan of the version and reads it back with 'terraform show -json'
+func (m *Manager) runPlan(ctx context.Context, opts *Options, versionInfo *VersionInfo) (*savedPlan, error) {
+	if err := m.fileUtils.EnsureDirectory(tmpDir(opts)); err != nil {
+		return nil, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+
+	varFile, err := m.prepareVarFile(ctx, opts, versionInfo)
+	if err != nil {
+		return nil, err
+	}
+
+	planFile := filepath.Join(tmpDir(opts), "tfplan")
+	result, err := m.tfRunner.Plan(ctx, &terraform.PlanOptions{
+		Environment: opts.Environment,
+		VarFile:     varFile,
+		Options:     opts.TerraformOpts,
+		Out:         planFile,
+		Reinit:      opts.Reinit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("terraform plan failed: %w", err)
+	}
+	if !result.Success {
+		return nil, fmt.Errorf("terraform plan failed: %s", result.ErrorOutput)
+	}
+
+	data, err := m.tfRunner.ShowPlanJSON(ctx, opts.Environment, planFile)
+	if err != nil {
+		return nil, err
+	}
+	p, err := plan.ParseJSON(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return &savedPlan{
+		File:  planFile,
+		Plan:  p,
+		Check: plan.NewCheckResult(p),
+	}, nil
+}
+
+// checkPlan applies the plan rules of the environment and asks for typed confirmation of destructive changes
+func (m *Manager) checkPlan(opts *Options, saved *savedPlan) error {
+	fmt.Printf("\nPlan: %s\n", saved.Check.Summary)
+
+	destructive := saved.Plan.Destructive()
+	if len(destructive) > 0 {
+		fmt.Printf("\nThe following resources will be deleted or replaced:\n")
+		for _, c := range destructive {
+			fmt.Printf("  %-8s %s\n", c.Action, c.Address)
+		}
+	}
+
+	violations := plan.CheckRules(saved.Plan, opts.Environment.Deployment.PlanRules)
+	if len(violations) > 0 {
+		fmt.Printf("\nPlan rules of environment '%s':\n", opts.Environment.Name)
+		blocked := false
+		for _, v := range violations {
+			note := ""
+			if v.Overridable && opts.Force {
+				note = " (overridden with --force)"
+			} else {
+				blocked = true
+			}
+			fmt.Printf("  %-14s %s%s\n", v.Rule, v.Message, note)
+			saved.Check.Violations = append(saved.Check.Violations, v.Message)
+		}
+		if blocked {
+			return fmt.Errorf("apply blocked by plan rules")
+		}
+		saved.Check.Forced = true
+	}
+
+	return confirmDestructive(destructive, opts.ConfirmDestroy)
+}
+
+// confirmDestructive requires each deleted or replaced address to be typed, unless it was given with --confirm-destroy
+func confirmDestructive(changes []plan.Change, confirmed []string) error {
+	given := make(map[string]bool, len(confirmed))
+	for _, address := range confirmed {
+		given[address] = true
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	for _, c := range changes {
+		if given[c.Address] {
+			continue
+		}
+		fmt.Printf("\nType the address to confirm %s of %s: ", c.Action, c.Address)
+		input, _ := reader.ReadString('\n')
+		if strings.TrimSpace(input) != c.Address {
+			return fmt.Errorf("apply cancelled: %s was not confirmed", c.Address)
+		}
+	}
+	return nil
+}
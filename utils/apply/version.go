@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"tfvarenv/utils/aws"
+	"tfvarenv/utils/state"
 	"tfvarenv/utils/version"
 )
 
@@ -99,11 +100,22 @@ func (m *Manager) getLocalVersion(ctx context.Context, versionManager version.Ma
 		UploadedBy:  os.Getenv("USER"),
 		Size:        fileInfo.Size,
 	}
+	if latestVer != nil {
+		newVersion.ParentVersionID = latestVer.VersionID
+	}
 
 	if err := versionManager.AddVersion(ctx, newVersion); err != nil {
 		return nil, fmt.Errorf("failed to record version: %w", err)
 	}
 
+	// The uploaded version becomes the parent of the default local file
+	if varFile == opts.Environment.Local.TFVarsPath {
+		stateManager := state.NewManager(m.fileUtils)
+		if err := stateManager.SetParentVersion(opts.Environment.Name, newVersion.VersionID, newVersion.Hash); err != nil {
+			fmt.Printf("Warning: Failed to update local state: %v\n", err)
+		}
+	}
+
 	return &VersionInfo{
 		Version:    newVersion,
 		IsNew:      true,
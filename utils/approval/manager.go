@@ -0,0 +1,212 @@
+package approval
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"tfvarenv/config"
+	"tfvarenv/utils/aws"
+	"tfvarenv/utils/plan"
+)
+
+var idPattern = regexp.MustCompile(`^[0-9a-f]+$`)
+
+// Manager stores approval requests in S3
+type Manager interface {
+	Create(ctx context.Context, versionID string, summary *plan.Summary) (*Request, error)
+	Get(ctx context.Context, id string) (*Request, error)
+	List(ctx context.Context) ([]*Request, error)
+	Review(ctx context.Context, id string, approve bool, comment string) (*Request, error)
+	Validate(ctx context.Context, id string) (*Request, error)
+	MarkApplied(ctx context.Context, req *Request) error
+}
+
+type manager struct {
+	awsClient aws.Client
+	env       *config.Environment
+}
+
+// NewManager creates a new approval manager for the environment
+func NewManager(awsClient aws.Client, env *config.Environment) Manager {
+	return &manager{
+		awsClient: awsClient,
+		env:       env,
+	}
+}
+
+// Create stores a pending request for the version, requested by the current identity
+func (m *manager) Create(ctx context.Context, versionID string, summary *plan.Summary) (*Request, error) {
+	identity, err := m.awsClient.GetCallerIdentity(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := newID()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	req := &Request{
+		ID:          id,
+		Environment: m.env.Name,
+		VersionID:   versionID,
+		PlanSummary: summary,
+		Status:      StatusPending,
+		RequestedBy: identity.Arn,
+		RequestedAt: now,
+		ExpiresAt:   now.Add(m.env.Deployment.GetApprovalTTL()),
+	}
+	if err := m.save(ctx, req); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+func (m *manager) Get(ctx context.Context, id string) (*Request, error) {
+	if !idPattern.MatchString(id) {
+		return nil, fmt.Errorf("invalid approval request ID: %s", id)
+	}
+
+	output, err := m.awsClient.DownloadFile(ctx, &aws.DownloadInput{
+		Bucket: m.env.S3.Bucket,
+		Key:    m.key(id),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("approval request %s not found: %w", id, err)
+	}
+
+	var req Request
+	if err := json.Unmarshal(output.Content, &req); err != nil {
+		return nil, fmt.Errorf("failed to decode approval request: %w", err)
+	}
+	return &req, nil
+}
+
+// List returns all requests of the environment, newest first
+func (m *manager) List(ctx context.Context) ([]*Request, error) {
+	objects, err := m.awsClient.ListObjects(ctx, m.env.S3.Bucket, m.env.GetApprovalPrefix())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list approval requests: %w", err)
+	}
+
+	requests := make([]*Request, 0, len(objects))
+	for _, obj := range objects {
+		if !strings.HasSuffix(obj.Key, ".json") {
+			continue
+		}
+		req, err := m.Get(ctx, strings.TrimSuffix(path.Base(obj.Key), ".json"))
+		if err != nil {
+			continue
+		}
+		requests = append(requests, req)
+	}
+
+	sort.Slice(requests, func(i, j int) bool {
+		return requests[i].RequestedAt.After(requests[j].RequestedAt)
+	})
+	return requests, nil
+}
+
+// Review approves or rejects a pending request. The reviewer must be a different identity than the requester.
+func (m *manager) Review(ctx context.Context, id string, approve bool, comment string) (*Request, error) {
+	req, err := m.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if req.Status != StatusPending {
+		return nil, fmt.Errorf("approval request %s is %s", id, req.Status)
+	}
+	if req.IsExpired(time.Now()) {
+		return nil, fmt.Errorf("approval request %s expired at %s", id, req.ExpiresAt.Format("2006-01-02 15:04:05"))
+	}
+
+	identity, err := m.awsClient.GetCallerIdentity(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if identity.Arn == req.RequestedBy {
+		return nil, fmt.Errorf("approval request %s must be reviewed by a different identity than the requester (%s)", id, req.RequestedBy)
+	}
+
+	req.Status = StatusRejected
+	if approve {
+		req.Status = StatusApproved
+	}
+	req.ReviewedBy = identity.Arn
+	req.ReviewedAt = time.Now()
+	req.Comment = comment
+
+	if err := m.save(ctx, req); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// Validate returns the request if it is approved and has not expired
+func (m *manager) Validate(ctx context.Context, id string) (*Request, error) {
+	req, err := m.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if req.Environment != m.env.Name {
+		return nil, fmt.Errorf("approval request %s is for environment %s", id, req.Environment)
+	}
+	if req.Status != StatusApproved {
+		return nil, fmt.Errorf("approval request %s is %s", id, req.Status)
+	}
+	if req.IsExpired(time.Now()) {
+		return nil, fmt.Errorf("approval request %s expired at %s", id, req.ExpiresAt.Format("2006-01-02 15:04:05"))
+	}
+	return req, nil
+}
+
+// MarkApplied records that the approved request has been used
+func (m *manager) MarkApplied(ctx context.Context, req *Request) error {
+	identity, err := m.awsClient.GetCallerIdentity(ctx)
+	if err != nil {
+		return err
+	}
+	req.Status = StatusApplied
+	req.AppliedBy = identity.Arn
+	req.AppliedAt = time.Now()
+	return m.save(ctx, req)
+}
+
+func (m *manager) save(ctx context.Context, req *Request) error {
+	data, err := json.MarshalIndent(req, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal approval request: %w", err)
+	}
+
+	input := &aws.UploadInput{
+		Bucket:      m.env.S3.Bucket,
+		Key:         m.key(req.ID),
+		Content:     data,
+		ContentType: "application/json",
+	}
+	if _, err := m.awsClient.UploadFile(ctx, input); err != nil {
+		return fmt.Errorf("failed to save approval request: %w", err)
+	}
+	return nil
+}
+
+func (m *manager) key(id string) string {
+	return m.env.GetApprovalPrefix() + id + ".json"
+}
+
+func newID() (string, error) {
+	b := make([]byte, 6)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate request ID: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
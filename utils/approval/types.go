@@ -0,0 +1,38 @@
+package approval
+
+import (
+	"time"
+
+	"tfvarenv/utils/plan"
+)
+
+// Request statuses
+const (
+	StatusPending  = "pending"
+	StatusApproved = "approved"
+	StatusRejected = "rejected"
+	StatusApplied  = "applied"
+)
+
+// Request represents an approval request for applying a version
+type Request struct {
+	ID          string        `json:"id"`
+	Environment string        `json:"environment"`
+	VersionID   string        `json:"version_id"`
+	PlanSummary *plan.Summary `json:"plan_summary,omitempty"`
+	Status      string        `json:"status"`
+	// RequestedBy and ReviewedBy are STS caller ARNs
+	RequestedBy string    `json:"requested_by"`
+	RequestedAt time.Time `json:"requested_at"`
+	ExpiresAt   time.Time `json:"expires_at"`
+	ReviewedBy  string    `json:"reviewed_by,omitempty"`
+	ReviewedAt  time.Time `json:"reviewed_at,omitempty"`
+	Comment     string    `json:"comment,omitempty"`
+	AppliedBy   string    `json:"applied_by,omitempty"`
+	AppliedAt   time.Time `json:"applied_at,omitempty"`
+}
+
+// IsExpired reports whether the request can no longer be approved or applied
+func (r *Request) IsExpired(now time.Time) bool {
+	return !r.ExpiresAt.IsZero() && now.After(r.ExpiresAt)
+}
@@ -11,13 +11,17 @@ import (
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
 	"github.com/aws/aws-sdk-go-v2/service/sts"
 )
 
 type client struct {
-	cfg       aws.Config
-	s3Client  *s3.Client
-	stsClient *sts.Client
+	cfg           aws.Config
+	s3Client      *s3.Client
+	stsClient     *sts.Client
+	ssmClient     *ssm.Client
+	secretsClient *secretsmanager.Client
 }
 
 // NewClient creates a new AWS client
@@ -39,9 +43,11 @@ func NewClient(region string) (Client, error) {
 	}
 
 	return &client{
-		cfg:       cfg,
-		s3Client:  s3.NewFromConfig(cfg),
-		stsClient: sts.NewFromConfig(cfg),
+		cfg:           cfg,
+		s3Client:      s3.NewFromConfig(cfg),
+		stsClient:     sts.NewFromConfig(cfg),
+		ssmClient:     ssm.NewFromConfig(cfg),
+		secretsClient: secretsmanager.NewFromConfig(cfg),
 	}, nil
 }
 
@@ -53,6 +59,19 @@ func (c *client) GetAccountID(ctx context.Context) (string, error) {
 	return *output.Account, nil
 }
 
+// GetCallerIdentity returns the account and ARN of the current credentials
+func (c *client) GetCallerIdentity(ctx context.Context) (*CallerIdentity, error) {
+	output, err := c.stsClient.GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get caller identity: %w", err)
+	}
+	return &CallerIdentity{
+		Account: aws.ToString(output.Account),
+		Arn:     aws.ToString(output.Arn),
+		UserID:  aws.ToString(output.UserId),
+	}, nil
+}
+
 func (c *client) CheckBucketVersioning(ctx context.Context, bucket string) error {
 	output, err := c.s3Client.GetBucketVersioning(ctx, &s3.GetBucketVersioningInput{
 		Bucket: aws.String(bucket),
@@ -68,13 +87,21 @@ func (c *client) CheckBucketVersioning(ctx context.Context, bucket string) error
 }
 
 func (c *client) UploadFile(ctx context.Context, input *UploadInput) (*UploadOutput, error) {
-	result, err := c.s3Client.PutObject(ctx, &s3.PutObjectInput{
+	putInput := &s3.PutObjectInput{
 		Bucket:      aws.String(input.Bucket),
 		Key:         aws.String(input.Key),
 		Body:        bytes.NewReader(input.Content),
 		ContentType: aws.String("application/x-tfvars"),
 		Metadata:    input.Metadata,
-	})
+	}
+	if input.IfMatch != "" {
+		putInput.IfMatch = aws.String(input.IfMatch)
+	}
+	if input.IfNoneMatch != "" {
+		putInput.IfNoneMatch = aws.String(input.IfNoneMatch)
+	}
+
+	result, err := c.s3Client.PutObject(ctx, putInput)
 	if err != nil {
 		return nil, fmt.Errorf("failed to upload file: %w", err)
 	}
@@ -108,6 +135,7 @@ func (c *client) DownloadFile(ctx context.Context, input *DownloadInput) (*Downl
 	return &DownloadOutput{
 		Content:     content,
 		VersionID:   *result.VersionId,
+		ETag:        aws.ToString(result.ETag),
 		Metadata:    result.Metadata,
 		ContentType: *result.ContentType,
 	}, nil
@@ -151,3 +179,84 @@ func (c *client) ListVersions(ctx context.Context, input *ListVersionsInput) (*L
 		NextMarker:  aws.ToString(result.NextKeyMarker),
 	}, nil
 }
+
+// GetParameter returns the decrypted value of an SSM parameter
+func (c *client) GetParameter(ctx context.Context, name string) (string, error) {
+	output, err := c.ssmClient.GetParameter(ctx, &ssm.GetParameterInput{
+		Name:           aws.String(name),
+		WithDecryption: aws.Bool(true),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to get parameter %s: %w", name, err)
+	}
+	return aws.ToString(output.Parameter.Value), nil
+}
+
+// GetSecretValue returns the string value of a Secrets Manager secret
+func (c *client) GetSecretValue(ctx context.Context, secretID string) (string, error) {
+	output, err := c.secretsClient.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{
+		SecretId: aws.String(secretID),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to get secret %s: %w", secretID, err)
+	}
+	if output.SecretString == nil {
+		return "", fmt.Errorf("secret %s has no string value", secretID)
+	}
+	return *output.SecretString, nil
+}
+
+// ListObjects returns all objects under the prefix
+func (c *client) ListObjects(ctx context.Context, bucket, prefix string) ([]ObjectInfo, error) {
+	objects := make([]ObjectInfo, 0)
+	paginator := s3.NewListObjectsV2Paginator(c.s3Client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(bucket),
+		Prefix: aws.String(prefix),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list objects: %w", err)
+		}
+		for _, obj := range page.Contents {
+			objects = append(objects, ObjectInfo{
+				Key:          aws.ToString(obj.Key),
+				Size:         aws.ToInt64(obj.Size),
+				LastModified: aws.ToTime(obj.LastModified),
+			})
+		}
+	}
+	return objects, nil
+}
+
+// ListPrefixes returns the common prefixes directly under the prefix, e.g. "records/2024/"
+func (c *client) ListPrefixes(ctx context.Context, bucket, prefix string) ([]string, error) {
+	prefixes := make([]string, 0)
+	paginator := s3.NewListObjectsV2Paginator(c.s3Client, &s3.ListObjectsV2Input{
+		Bucket:    aws.String(bucket),
+		Prefix:    aws.String(prefix),
+		Delimiter: aws.String("/"),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list prefixes: %w", err)
+		}
+		for _, p := range page.CommonPrefixes {
+			prefixes = append(prefixes, aws.ToString(p.Prefix))
+		}
+	}
+	return prefixes, nil
+}
+
+// DeleteObject deletes the object; in versioned buckets this adds a delete marker
+func (c *client) DeleteObject(ctx context.Context, bucket, key string) error {
+	_, err := c.s3Client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete object: %w", err)
+	}
+	return nil
+}
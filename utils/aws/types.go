@@ -8,10 +8,16 @@ import (
 // Client defines the interface for AWS operations
 type Client interface {
 	GetAccountID(ctx context.Context) (string, error)
+	GetCallerIdentity(ctx context.Context) (*CallerIdentity, error)
 	CheckBucketVersioning(ctx context.Context, bucket string) error
 	UploadFile(ctx context.Context, input *UploadInput) (*UploadOutput, error)
 	DownloadFile(ctx context.Context, input *DownloadInput) (*DownloadOutput, error)
 	ListVersions(ctx context.Context, input *ListVersionsInput) (*ListVersionsOutput, error)
+	ListObjects(ctx context.Context, bucket, prefix string) ([]ObjectInfo, error)
+	ListPrefixes(ctx context.Context, bucket, prefix string) ([]string, error)
+	DeleteObject(ctx context.Context, bucket, key string) error
+	GetParameter(ctx context.Context, name string) (string, error)
+	GetSecretValue(ctx context.Context, secretID string) (string, error)
 }
 
 // UploadInput represents input parameters for file upload
@@ -22,6 +28,10 @@ type UploadInput struct {
 	ContentType string
 	Description string
 	Metadata    map[string]string
+	// IfMatch uploads only if the object still has this ETag
+	IfMatch string
+	// IfNoneMatch "*" uploads only if the object does not exist
+	IfNoneMatch string
 }
 
 // UploadOutput represents the result of a file upload
@@ -41,6 +51,7 @@ type DownloadInput struct {
 type DownloadOutput struct {
 	Content     []byte
 	VersionID   string
+	ETag        string
 	Metadata    map[string]string
 	ContentType string
 }
@@ -70,3 +81,17 @@ type VersionInfo struct {
 	IsLatest    bool
 	Metadata    map[string]string
 }
+
+// CallerIdentity represents the identity of the AWS credentials in use
+type CallerIdentity struct {
+	Account string
+	Arn     string
+	UserID  string
+}
+
+// ObjectInfo represents an object returned by ListObjects
+type ObjectInfo struct {
+	Key          string
+	Size         int64
+	LastModified time.Time
+}
@@ -0,0 +1,249 @@
+package changelog
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"tfvarenv/utils/deployment"
+	"tfvarenv/utils/tfvars"
+	"tfvarenv/utils/version"
+)
+
+const refLatest = "latest"
+
+// clockSkew allows for the difference between the S3 clock that timestamps versions
+// and the local clock that timestamps deployments
+const clockSkew = 5 * time.Minute
+
+// Manager builds changelogs from the deployment history and version index
+type Manager interface {
+	Generate(ctx context.Context, from, to Ref) (*Changelog, error)
+}
+
+type manager struct {
+	envName           string
+	versionManager    version.Manager
+	deploymentManager deployment.Manager
+}
+
+// NewManager creates a new changelog manager
+func NewManager(envName string, versionManager version.Manager, deploymentManager deployment.Manager) Manager {
+	return &manager{
+		envName:           envName,
+		versionManager:    versionManager,
+		deploymentManager: deploymentManager,
+	}
+}
+
+// Generate builds the changelog between two refs. An unset "to" means the latest
+// deployment, and an unset "from" means the deployment before "to".
+// Only the deployments between the two are read from the history.
+func (m *manager) Generate(ctx context.Context, from, to Ref) (*Changelog, error) {
+	versions, err := m.versionManager.GetVersions(ctx, &version.QueryOptions{SortByDate: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get versions: %w", err)
+	}
+
+	if to.IsZero() {
+		to = Ref{Deployment: refLatest}
+	}
+	toDeploy, err := m.resolve(ctx, versions, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve --to: %w", err)
+	}
+	if toDeploy == nil {
+		return nil, fmt.Errorf("no deployment found for --to")
+	}
+
+	var fromDeploy *deployment.Record
+	if from.IsZero() {
+		fromDeploy, err = m.latestDeployment(ctx, deployment.QueryOptions{
+			Before: toDeploy.Timestamp.Add(-time.Nanosecond),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve --from: %w", err)
+		}
+	} else if fromDeploy, err = m.resolve(ctx, versions, from); err != nil {
+		return nil, fmt.Errorf("failed to resolve --from: %w", err)
+	}
+
+	changelog := &Changelog{
+		Environment: m.envName,
+		From:        &Endpoint{Deployment: fromDeploy},
+		To:          &Endpoint{Deployment: toDeploy},
+	}
+
+	changelog.To.Version = findVersion(versions, toDeploy.VersionID)
+	if fromDeploy != nil {
+		changelog.From.Version = findVersion(versions, fromDeploy.VersionID)
+	}
+
+	changelog.Versions, changelog.Rollback = intermediateVersions(versions, changelog.From.Version, changelog.To.Version)
+
+	query := deployment.QueryOptions{
+		Status:  deployment.StatusSuccess,
+		Command: deployment.CommandApply,
+		Before:  toDeploy.Timestamp,
+	}
+	if fromDeploy != nil {
+		query.Since = fromDeploy.Timestamp
+	}
+	deployments, err := m.deploymentManager.QueryDeployments(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query deployments: %w", err)
+	}
+	changelog.Deployments = deploymentsBetween(deployments, fromDeploy, toDeploy)
+
+	changes, err := m.diff(ctx, fromDeploy, toDeploy)
+	if err != nil {
+		return nil, err
+	}
+	changelog.Changes = changes
+
+	return changelog, nil
+}
+
+func (m *manager) resolve(ctx context.Context, versions []version.Version, ref Ref) (*deployment.Record, error) {
+	if !ref.At.IsZero() {
+		return m.deploymentManager.GetActiveDeploymentAt(ctx, ref.At)
+	}
+
+	if ref.Deployment == refLatest {
+		return m.latestDeployment(ctx, deployment.QueryOptions{})
+	}
+
+	// デプロイIDを優先し、見つからなければバージョンIDとして扱う
+	if deployment.IsID(ref.Deployment) {
+		d, err := m.deploymentManager.GetRecord(ctx, ref.Deployment)
+		if err == nil && d.Command == deployment.CommandApply && d.Status == deployment.StatusSuccess {
+			return d, nil
+		}
+	}
+
+	// バージョンはアップロード後にしかデプロイされないので、それ以降の記録だけを探す
+	var found *deployment.Record
+	for _, v := range versions {
+		if !strings.HasPrefix(v.VersionID, ref.Deployment) {
+			continue
+		}
+		d, err := m.latestDeployment(ctx, deployment.QueryOptions{
+			VersionID: v.VersionID,
+			Since:     v.Timestamp.Add(-clockSkew),
+		})
+		if err != nil {
+			return nil, err
+		}
+		if d != nil && (found == nil || d.Timestamp.After(found.Timestamp)) {
+			found = d
+		}
+	}
+	if found == nil {
+		return nil, fmt.Errorf("no successful deployment with ID or version %s", ref.Deployment)
+	}
+	return found, nil
+}
+
+// latestDeployment returns the newest successful apply matching the options, or nil if there is none
+func (m *manager) latestDeployment(ctx context.Context, options deployment.QueryOptions) (*deployment.Record, error) {
+	options.Status = deployment.StatusSuccess
+	options.Command = deployment.CommandApply
+	options.Limit = 1
+	records, err := m.deploymentManager.QueryDeployments(ctx, options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query deployments: %w", err)
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+	return &records[0], nil
+}
+
+func (m *manager) diff(ctx context.Context, fromDeploy, toDeploy *deployment.Record) ([]tfvars.Change, error) {
+	var oldFile *tfvars.File
+	if fromDeploy != nil {
+		f, err := m.load(ctx, fromDeploy.VersionID)
+		if err != nil {
+			return nil, err
+		}
+		oldFile = f
+	}
+
+	newFile, err := m.load(ctx, toDeploy.VersionID)
+	if err != nil {
+		return nil, err
+	}
+
+	return tfvars.Diff(oldFile, newFile), nil
+}
+
+func (m *manager) load(ctx context.Context, versionID string) (*tfvars.File, error) {
+	content, err := m.versionManager.GetVersionContent(ctx, versionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download version %s: %w", versionID, err)
+	}
+	f, err := tfvars.Parse(content, "version "+versionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse version %s: %w", versionID, err)
+	}
+	return f, nil
+}
+
+func findVersion(versions []version.Version, versionID string) *version.Version {
+	for i := range versions {
+		if versions[i].VersionID == versionID {
+			return &versions[i]
+		}
+	}
+	return nil
+}
+
+// intermediateVersions returns the versions uploaded after "from" up to and including "to", oldest first
+func intermediateVersions(versions []version.Version, from, to *version.Version) ([]version.Version, bool) {
+	if to == nil {
+		return nil, false
+	}
+
+	var since time.Time
+	if from != nil {
+		if from.VersionID == to.VersionID {
+			return nil, false
+		}
+		if to.Timestamp.Before(from.Timestamp) {
+			return nil, true
+		}
+		since = from.Timestamp
+	}
+
+	var result []version.Version
+	for _, v := range versions {
+		if v.Timestamp.After(to.Timestamp) || !v.Timestamp.After(since) {
+			continue
+		}
+		result = append(result, v)
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Timestamp.Before(result[j].Timestamp)
+	})
+	return result, false
+}
+
+// deploymentsBetween returns the deployments after "from" up to and including "to", oldest first
+func deploymentsBetween(deployments []deployment.Record, from, to *deployment.Record) []deployment.Record {
+	var result []deployment.Record
+	for _, d := range deployments {
+		if d.Timestamp.After(to.Timestamp) {
+			continue
+		}
+		if from != nil && !d.Timestamp.After(from.Timestamp) {
+			continue
+		}
+		result = append(result, d)
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Timestamp.Before(result[j].Timestamp)
+	})
+	return result
+}
@@ -0,0 +1,106 @@
+package changelog
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"tfvarenv/utils/tfvars"
+	"tfvarenv/utils/version"
+)
+
+// Render writes the changelog in the given format
+func Render(w io.Writer, c *Changelog, format string) error {
+	switch format {
+	case FormatMarkdown:
+		return renderMarkdown(w, c)
+	case FormatJSON:
+		data, err := json.MarshalIndent(c, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal changelog: %w", err)
+		}
+		_, err = fmt.Fprintln(w, string(data))
+		return err
+	default:
+		return fmt.Errorf("unsupported format: %s (must be %s or %s)", format, FormatMarkdown, FormatJSON)
+	}
+}
+
+func renderMarkdown(w io.Writer, c *Changelog) error {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# Changelog: %s\n\n", c.Environment)
+	fmt.Fprintf(&b, "- **From:** %s\n", describeEndpoint(c.From))
+	fmt.Fprintf(&b, "- **To:** %s\n", describeEndpoint(c.To))
+	if c.Rollback {
+		b.WriteString("\n> **Note:** This is a rollback to an older version.\n")
+	}
+
+	b.WriteString("\n## Versions\n\n")
+	if len(c.Versions) == 0 {
+		b.WriteString("No new versions.\n")
+	}
+	for _, v := range c.Versions {
+		description := v.Description
+		if description == "" {
+			description = "(no description)"
+		}
+		fmt.Fprintf(&b, "- `%s` %s by %s: %s\n",
+			version.ShortID(v.VersionID),
+			v.Timestamp.Format("2006-01-02 15:04:05"),
+			v.UploadedBy,
+			description)
+	}
+
+	if len(c.Deployments) > 0 {
+		b.WriteString("\n## Deployments\n\n")
+		for _, d := range c.Deployments {
+			fmt.Fprintf(&b, "- `%s` deployed %s by %s (deployment `%s`)\n",
+				version.ShortID(d.VersionID),
+				d.Timestamp.Format("2006-01-02 15:04:05"),
+				d.DeployedBy,
+				d.ID)
+		}
+	}
+
+	b.WriteString("\n## Variable Changes\n\n")
+	if len(c.Changes) == 0 {
+		b.WriteString("No variable changes.\n")
+	} else {
+		b.WriteString("| Variable | Change | Old Value | New Value |\n")
+		b.WriteString("|----------|--------|-----------|-----------|\n")
+		for _, change := range c.Changes {
+			fmt.Fprintf(&b, "| `%s` | %s | %s | %s |\n",
+				change.Name,
+				change.Type,
+				markdownValue(change.OldValue, change.Type == tfvars.ChangeAdded),
+				markdownValue(change.NewValue, change.Type == tfvars.ChangeRemoved))
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func describeEndpoint(e *Endpoint) string {
+	if e == nil || e.Deployment == nil {
+		return "nothing deployed"
+	}
+	return fmt.Sprintf("`%s` deployed %s by %s",
+		version.ShortID(e.Deployment.VersionID),
+		e.Deployment.Timestamp.Format("2006-01-02 15:04:05"),
+		e.Deployment.DeployedBy)
+}
+
+func markdownValue(value string, empty bool) string {
+	if empty {
+		return ""
+	}
+	// テーブルが崩れないように改行とパイプをエスケープする
+	value = strings.ReplaceAll(value, "|", "\\|")
+	if strings.Contains(value, "\n") {
+		return strings.ReplaceAll(value, "\n", "<br>")
+	}
+	return "`" + value + "`"
+}
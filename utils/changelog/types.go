@@ -0,0 +1,44 @@
+package changelog
+
+import (
+	"time"
+
+	"tfvarenv/utils/deployment"
+	"tfvarenv/utils/tfvars"
+	"tfvarenv/utils/version"
+)
+
+const (
+	FormatMarkdown = "markdown"
+	FormatJSON     = "json"
+)
+
+// Ref identifies one end of a changelog. Either a deployment (a version ID
+// prefix or "latest") or a point in time can be given.
+type Ref struct {
+	Deployment string
+	At         time.Time
+}
+
+// IsZero reports whether the ref is unset
+func (r Ref) IsZero() bool {
+	return r.Deployment == "" && r.At.IsZero()
+}
+
+// Endpoint represents a resolved end of a changelog
+type Endpoint struct {
+	Deployment *deployment.Record `json:"deployment,omitempty"`
+	Version    *version.Version   `json:"version,omitempty"`
+}
+
+// Changelog represents the changes between two deployments of an environment
+type Changelog struct {
+	Environment string              `json:"environment"`
+	From        *Endpoint           `json:"from"`
+	To          *Endpoint           `json:"to"`
+	Versions    []version.Version   `json:"versions"`
+	Deployments []deployment.Record `json:"deployments"`
+	Changes     []tfvars.Change     `json:"changes"`
+	// Rollback is set when the "to" version is older than the "from" version
+	Rollback bool `json:"rollback,omitempty"`
+}
@@ -3,30 +3,58 @@ package command
 import (
 	"context"
 	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
 
 	"tfvarenv/config"
 	"tfvarenv/utils/aws"
 	"tfvarenv/utils/file"
+	"tfvarenv/utils/identity"
+	"tfvarenv/utils/state"
 	"tfvarenv/utils/terraform"
 )
 
+const (
+	// EnvironmentVariable overrides the environment selected with 'tfvarenv use'
+	EnvironmentVariable = "TFVARENV_ENV"
+	StateFile           = "state"
+)
+
+var (
+	// invocationDir is the directory tfvarenv was started in, before changing to the project root
+	invocationDir     string
+	invocationDirOnce sync.Once
+)
+
 type commandUtils struct {
 	ctx        context.Context
 	awsClient  aws.Client
 	fileUtils  file.Utils
 	tfRunner   terraform.Runner
+	idResolver identity.Resolver
 	cfgManager config.Manager
+	stManager  state.Manager
 }
 
 // NewUtils creates a new command utilities instance
 func NewUtils() (Utils, error) {
 	ctx := context.Background()
 
+	invocationDirOnce.Do(func() {
+		invocationDir, _ = os.Getwd()
+	})
+
 	cfgManager, err := config.NewManager()
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize config manager: %w", err)
 	}
 
+	// 設定ファイルのあるディレクトリを基準にパスを解決する
+	if err := os.Chdir(cfgManager.GetRootDir()); err != nil {
+		return nil, fmt.Errorf("failed to change to project root: %w", err)
+	}
+
 	defaultRegion, err := cfgManager.GetDefaultRegion()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get default region: %w", err)
@@ -38,14 +66,16 @@ func NewUtils() (Utils, error) {
 	}
 
 	fileUtils := file.NewUtils()
-	tfRunner := terraform.NewRunner(awsClient, fileUtils)
+	tfRunner := terraform.NewRunner(awsClient, fileUtils, cfgManager.GetTerraformConfig())
 
 	return &commandUtils{
 		ctx:        ctx,
 		awsClient:  awsClient,
 		fileUtils:  fileUtils,
 		tfRunner:   tfRunner,
+		idResolver: identity.NewResolver(awsClient, cfgManager.GetIdentityConfig()),
 		cfgManager: cfgManager,
+		stManager:  state.NewManager(fileUtils),
 	}, nil
 }
 
@@ -117,6 +147,55 @@ func (c *commandUtils) GetTerraformRunner() terraform.Runner {
 	return c.tfRunner
 }
 
+func (c *commandUtils) GetIdentityResolver() identity.Resolver {
+	return c.idResolver
+}
+
+func (c *commandUtils) GetStateManager() state.Manager {
+	return c.stManager
+}
+
+// GetCurrentEnvironment returns the current environment, or nil if none is selected
+func (c *commandUtils) GetCurrentEnvironment() (*CurrentEnvironment, error) {
+	if name := os.Getenv(EnvironmentVariable); name != "" {
+		return &CurrentEnvironment{Name: name, Source: EnvironmentVariable}, nil
+	}
+
+	name, err := c.stManager.GetCurrentEnvironment()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current environment: %w", err)
+	}
+	if name == "" {
+		return nil, nil
+	}
+	return &CurrentEnvironment{Name: name, Source: StateFile}, nil
+}
+
+// ResolveEnvironmentName returns the environment given as the first argument,
+// falling back to the current environment
+func (c *commandUtils) ResolveEnvironmentName(args []string) (string, error) {
+	if len(args) > 0 {
+		return args[0], nil
+	}
+
+	current, err := c.GetCurrentEnvironment()
+	if err != nil {
+		return "", err
+	}
+	if current == nil {
+		return "", fmt.Errorf("no environment specified; pass it as an argument, run 'tfvarenv use [environment]' or set %s", EnvironmentVariable)
+	}
+	return current.Name, nil
+}
+
+// ResolvePath resolves a path given on the command line against the directory tfvarenv was started in
+func (c *commandUtils) ResolvePath(path string) string {
+	if path == "" || filepath.IsAbs(path) || invocationDir == "" {
+		return path
+	}
+	return filepath.Join(invocationDir, path)
+}
+
 func (c *commandUtils) GetContext() context.Context {
 	return c.ctx
 }
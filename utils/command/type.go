@@ -6,6 +6,8 @@ import (
 	"tfvarenv/config"
 	"tfvarenv/utils/aws"
 	"tfvarenv/utils/file"
+	"tfvarenv/utils/identity"
+	"tfvarenv/utils/state"
 	"tfvarenv/utils/terraform"
 )
 
@@ -16,11 +18,22 @@ type Utils interface {
 	GetAWSClientWithRegion(region string) (aws.Client, error)
 	GetFileUtils() file.Utils
 	GetTerraformRunner() terraform.Runner
+	GetIdentityResolver() identity.Resolver
+	GetStateManager() state.Manager
+	GetCurrentEnvironment() (*CurrentEnvironment, error)
+	ResolveEnvironmentName(args []string) (string, error)
+	ResolvePath(path string) string
 	GetContext() context.Context
 	AddEnvironment(env *config.Environment) error
 	ListEnvironments() ([]string, error)
 }
 
+// CurrentEnvironment represents the environment used when the argument is omitted
+type CurrentEnvironment struct {
+	Name   string
+	Source string // EnvironmentVariable or StateFile
+}
+
 // CommandInput represents common input parameters for commands
 type CommandInput struct {
 	EnvName     string
@@ -2,20 +2,23 @@ package deployment
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"sort"
 	"time"
 
 	"tfvarenv/config"
 	"tfvarenv/utils/aws"
 )
 
+// HistoryFormatVersion is the format of the legacy single-file history
 const HistoryFormatVersion = "1.0"
 
 const (
 	StatusSuccess = "success"
 	StatusFailure = "failure"
+	// StatusInProgress is written before terraform starts and replaced when it exits
+	StatusInProgress = "in_progress"
+	// StatusInterrupted marks runs stopped by a signal
+	StatusInterrupted = "interrupted"
 )
 
 const (
@@ -27,15 +30,25 @@ const (
 	CommandApply   = "apply"
 	CommandPlan    = "plan"
 	CommandDestroy = "destroy"
+	// CommandProtect and CommandUnprotect record protection changes of the environment
+	CommandProtect   = "protect"
+	CommandUnprotect = "unprotect"
 )
 
+// Manager stores deployment records as one S3 object each, with a head pointer
+// to the latest deployment and the runs in progress
 type Manager interface {
 	AddRecord(ctx context.Context, record *Record) error
+	UpdateRecord(ctx context.Context, record *Record) error
+	GetRecord(ctx context.Context, id string) (*Record, error)
 	GetHistory(ctx context.Context) (*History, error)
+	GetLatestInfo(ctx context.Context) (*LatestInfo, error)
 	GetLatestDeployment(ctx context.Context) (*Record, error)
-	GetStats(ctx context.Context) (*Stats, error)
+	GetInProgress(ctx context.Context) ([]Record, error)
 	QueryDeployments(ctx context.Context, options QueryOptions) ([]Record, error)
+	GetActiveDeploymentAt(ctx context.Context, at time.Time) (*Record, error)
 	MarkAsDestroyed(ctx context.Context) error
+	Migrate(ctx context.Context) (int, error)
 }
 
 type manager struct {
@@ -51,111 +64,197 @@ func NewManager(awsClient aws.Client, env *config.Environment) Manager {
 }
 
 func (m *manager) AddRecord(ctx context.Context, record *Record) error {
-	history, err := m.GetHistory(ctx)
-	if err != nil {
-		return fmt.Errorf("failed to get deployment history: %w", err)
+	if record.ID == "" {
+		record.ID = NewID(record.Timestamp)
 	}
 
-	// Add new record
-	history.Deployments = append(history.Deployments, *record)
+	if err := m.putRecord(ctx, record); err != nil {
+		return fmt.Errorf("failed to save deployment record: %w", err)
+	}
 
-	history.LatestDeployment = &LatestInfo{
-		Deployment:   record,
-		Status:       StatusActive,
-		ModifiedTime: time.Now(),
+	return m.updateHead(ctx, func(head *Head) bool {
+		return updateLatest(head, record)
+	})
+}
+
+// UpdateRecord replaces the record with the same ID, e.g. to complete an in-progress record
+func (m *manager) UpdateRecord(ctx context.Context, record *Record) error {
+	if record.ID == "" {
+		return fmt.Errorf("deployment record of %s has no ID", record.Timestamp.Format("2006-01-02 15:04:05"))
+	}
+
+	if err := m.putRecord(ctx, record); err != nil {
+		return fmt.Errorf("failed to save deployment record: %w", err)
 	}
 
-	// Sort deployments by timestamp (newest first)
-	sort.Slice(history.Deployments, func(i, j int) bool {
-		return history.Deployments[i].Timestamp.After(history.Deployments[j].Timestamp)
+	return m.updateHead(ctx, func(head *Head) bool {
+		return updateLatest(head, record)
 	})
+}
 
-	// Save updated history
-	if err := m.saveHistory(ctx, history); err != nil {
-		return fmt.Errorf("failed to save deployment history: %w", err)
+// updateLatest tracks runs in progress and makes a successful apply the latest deployment;
+// a failed or interrupted apply leaves the previous deployment active. It reports whether
+// the head changed.
+func updateLatest(head *Head, record *Record) bool {
+	changed := false
+	inProgress := head.InProgress[:0]
+	for _, id := range head.InProgress {
+		if id == record.ID && record.Status != StatusInProgress {
+			changed = true
+			continue
+		}
+		inProgress = append(inProgress, id)
+	}
+	head.InProgress = inProgress
+
+	if record.Status == StatusInProgress {
+		for _, id := range head.InProgress {
+			if id == record.ID {
+				return changed
+			}
+		}
+		head.InProgress = append(head.InProgress, record.ID)
+		return true
 	}
 
-	return nil
+	if record.Command != CommandApply || record.Status != StatusSuccess {
+		return changed
+	}
+	head.LatestDeployment = &LatestInfo{
+		Deployment:   record,
+		Status:       StatusActive,
+		ModifiedTime: time.Now(),
+	}
+	return true
 }
 
 // MarkAsDestroyed は環境の状態をdestroyedに設定
 func (m *manager) MarkAsDestroyed(ctx context.Context) error {
-	history, err := m.GetHistory(ctx)
-	if err != nil {
-		return fmt.Errorf("failed to get deployment history: %w", err)
-	}
+	return m.updateHead(ctx, func(head *Head) bool {
+		if head.LatestDeployment == nil {
+			head.LatestDeployment = &LatestInfo{}
+		}
+		head.LatestDeployment.Status = StatusDestroyed
+		head.LatestDeployment.ModifiedTime = time.Now()
+		return true
+	})
+}
 
-	if history.LatestDeployment == nil {
-		history.LatestDeployment = &LatestInfo{}
+// GetRecord returns the record with the ID, which may be abbreviated
+func (m *manager) GetRecord(ctx context.Context, id string) (*Record, error) {
+	// IDの先頭は時刻なので、その時間帯のパーティションだけを探す
+	since, before, ok := idTimeRange(id)
+	if !IsID(id) || !ok {
+		return nil, fmt.Errorf("deployment %s not found", id)
 	}
-	history.LatestDeployment.Status = StatusDestroyed
-	history.LatestDeployment.ModifiedTime = time.Now()
 
-	if err := m.saveHistory(ctx, history); err != nil {
-		return fmt.Errorf("failed to save deployment history: %w", err)
+	entries, err := m.listEntries(ctx, since, before)
+	if err != nil {
+		return nil, err
+	}
+	records := make([]Record, len(entries))
+	for i, e := range entries {
+		records[i].ID = e.id
+	}
+	found, err := FindRecord(records, id)
+	if err != nil {
+		return nil, err
 	}
 
-	return nil
+	for i := range entries {
+		if entries[i].id != found.ID {
+			continue
+		}
+		if err := m.loadEntries(ctx, entries[i:i+1]); err != nil {
+			return nil, err
+		}
+		return entries[i].record, nil
+	}
+	return nil, fmt.Errorf("deployment %s not found", id)
 }
 
+// GetHistory returns the head and every record, newest first. Prefer QueryDeployments
+// for large histories.
 func (m *manager) GetHistory(ctx context.Context) (*History, error) {
-	input := &aws.DownloadInput{
-		Bucket: m.env.S3.Bucket,
-		Key:    m.env.GetDeploymentHistoryKey(),
+	head, err := m.getHead(ctx)
+	if err != nil {
+		return nil, err
 	}
-
-	output, err := m.awsClient.DownloadFile(ctx, input)
+	records, err := m.query(ctx, time.Time{}, time.Time{}, 0, nil)
 	if err != nil {
-		// 履歴ファイルが存在しない場合は新規作成
-		return &History{
-			FormatVersion: HistoryFormatVersion,
-			Environment:   m.env.Name,
-			Deployments:   make([]Record, 0),
-		}, nil
+		return nil, err
 	}
 
-	var history History
-	if err := json.Unmarshal(output.Content, &history); err != nil {
-		return nil, fmt.Errorf("failed to decode deployment history: %w", err)
-	}
+	return &History{
+		FormatVersion:    head.FormatVersion,
+		Environment:      m.env.Name,
+		LatestDeployment: head.LatestDeployment,
+		Deployments:      records,
+	}, nil
+}
 
-	return &history, nil
+// GetLatestInfo returns the latest deployment and the environment status, or nil if never deployed
+func (m *manager) GetLatestInfo(ctx context.Context) (*LatestInfo, error) {
+	head, err := m.getHead(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return head.LatestDeployment, nil
 }
 
 func (m *manager) GetLatestDeployment(ctx context.Context) (*Record, error) {
-	history, err := m.GetHistory(ctx)
+	head, err := m.getHead(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get deployment history: %w", err)
 	}
 
-	if history.LatestDeployment != nil && history.LatestDeployment.Deployment != nil {
-		return history.LatestDeployment.Deployment, nil
+	if head.LatestDeployment != nil && head.LatestDeployment.Deployment != nil {
+		return head.LatestDeployment.Deployment, nil
 	}
 
-	if len(history.Deployments) > 0 {
-		return &history.Deployments[0], nil
+	records, err := m.query(ctx, time.Time{}, time.Time{}, 1, func(d *Record) bool {
+		return d.Command == CommandApply && d.Status == StatusSuccess
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get deployment history: %w", err)
 	}
-
-	return nil, nil
+	if len(records) == 0 {
+		return nil, nil
+	}
+	return &records[0], nil
 }
 
-func (m *manager) GetStats(ctx context.Context) (*Stats, error) {
-	history, err := m.GetHistory(ctx)
+// GetInProgress returns the records of runs that have not finished, newest first
+func (m *manager) GetInProgress(ctx context.Context) ([]Record, error) {
+	head, err := m.getHead(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get deployment history: %w", err)
+		return nil, err
+	}
+
+	records := make([]Record, 0, len(head.InProgress))
+	for i := len(head.InProgress) - 1; i >= 0; i-- {
+		record, err := m.GetRecord(ctx, head.InProgress[i])
+		if err != nil {
+			return nil, fmt.Errorf("failed to get deployment record: %w", err)
+		}
+		records = append(records, *record)
 	}
+	return records, nil
+}
 
+// NewStats summarizes the records, e.g. the result of QueryDeployments
+func NewStats(records []Record) *Stats {
 	stats := &Stats{
-		TotalDeployments:  len(history.Deployments),
+		TotalDeployments:  len(records),
 		CommonErrors:      make(map[string]int),
 		DeploymentsByUser: make(map[string]int),
 	}
 
 	var totalDuration time.Duration
-	for _, d := range history.Deployments {
+	for _, d := range records {
 		if d.Status == StatusSuccess {
 			stats.SuccessfulCount++
-		} else {
+		} else if d.Status != StatusInProgress {
 			stats.FailedCount++
 			if d.ErrorMessage != "" {
 				stats.CommonErrors[d.ErrorMessage]++
@@ -172,62 +271,118 @@ func (m *manager) GetStats(ctx context.Context) (*Stats, error) {
 		stats.AverageDuration = totalDuration / time.Duration(stats.TotalDeployments)
 	}
 
-	if len(history.Deployments) > 0 {
-		stats.LastDeployment = &history.Deployments[0]
+	if len(records) > 0 {
+		stats.LastDeployment = &records[0]
 	}
 
-	return stats, nil
+	return stats
 }
 
 func (m *manager) QueryDeployments(ctx context.Context, options QueryOptions) ([]Record, error) {
-	history, err := m.GetHistory(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get deployment history: %w", err)
-	}
-
-	var filtered []Record
-	for _, d := range history.Deployments {
-		if !options.Since.IsZero() && d.Timestamp.Before(options.Since) {
-			continue
-		}
-		if !options.Before.IsZero() && d.Timestamp.After(options.Before) {
-			continue
-		}
+	records, err := m.query(ctx, options.Since, options.Before, options.Limit, func(d *Record) bool {
 		if options.Status != "" && d.Status != options.Status {
-			continue
+			return false
+		}
+		if options.Command != "" && d.Command != options.Command {
+			return false
 		}
 		if options.DeployedBy != "" && d.DeployedBy != options.DeployedBy {
-			continue
+			return false
 		}
 		if options.VersionID != "" && d.VersionID != options.VersionID {
-			continue
+			return false
 		}
-		filtered = append(filtered, d)
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query deployments: %w", err)
 	}
+	return records, nil
+}
 
-	if options.Limit > 0 && len(filtered) > options.Limit {
-		filtered = filtered[:options.Limit]
+// GetActiveDeploymentAt returns the deployment that was active at the given time.
+// It returns nil if nothing was deployed or the environment was destroyed at that time.
+func (m *manager) GetActiveDeploymentAt(ctx context.Context, at time.Time) (*Record, error) {
+	head, err := m.getHead(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get deployment history: %w", err)
 	}
 
-	return filtered, nil
+	// 最新のデプロイがその時点で有効なら記録を探す必要はない
+	latest := head.LatestDeployment
+	if latest != nil && latest.Status == StatusActive && latest.Deployment != nil &&
+		latest.Deployment.Status == StatusSuccess && !latest.Deployment.Timestamp.After(at) {
+		return latest.Deployment, nil
+	}
+
+	var destroyedAt time.Time
+	if latest != nil && latest.Status == StatusDestroyed && !latest.ModifiedTime.After(at) {
+		destroyedAt = latest.ModifiedTime
+	}
+
+	records, err := m.query(ctx, time.Time{}, at, 1, func(d *Record) bool {
+		return d.Status == StatusSuccess && (d.Command == CommandApply || d.Command == CommandDestroy)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get deployment history: %w", err)
+	}
+	if len(records) == 0 || records[0].Command == CommandDestroy {
+		return nil, nil
+	}
+	if !destroyedAt.IsZero() && destroyedAt.After(records[0].Timestamp) {
+		return nil, nil
+	}
+	return &records[0], nil
 }
 
-func (m *manager) saveHistory(ctx context.Context, history *History) error {
-	data, err := json.MarshalIndent(history, "", "  ")
+// Migrate moves the records of the legacy single-file history to individual objects
+// and removes the file. It returns the number of records moved.
+func (m *manager) Migrate(ctx context.Context) (int, error) {
+	legacy, err := m.getLegacyHistory(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if legacy == nil {
+		return 0, nil
+	}
+
+	// 移行済みの記録は新しい方を残す
+	existing := make(map[string]bool)
+	entries, err := m.listEntries(ctx, time.Time{}, time.Time{})
 	if err != nil {
-		return fmt.Errorf("failed to marshal deployment history: %w", err)
+		return 0, err
+	}
+	for _, e := range entries {
+		if e.record == nil {
+			existing[e.id] = true
+		}
 	}
 
-	input := &aws.UploadInput{
-		Bucket:      m.env.S3.Bucket,
-		Key:         m.env.GetDeploymentHistoryKey(),
-		Content:     data,
-		ContentType: "application/json",
+	moved := 0
+	for i := range legacy.Deployments {
+		record := &legacy.Deployments[i]
+		if existing[record.ID] {
+			continue
+		}
+		if err := m.putRecord(ctx, record); err != nil {
+			return moved, fmt.Errorf("failed to save deployment record: %w", err)
+		}
+		moved++
 	}
 
-	if _, err := m.awsClient.UploadFile(ctx, input); err != nil {
-		return fmt.Errorf("failed to save deployment history: %w", err)
+	// ヘッドがなければ旧形式から作られるので、削除前に必ず保存する
+	err = m.updateHead(ctx, func(head *Head) bool {
+		if head.LatestDeployment == nil {
+			head.LatestDeployment = legacy.LatestDeployment
+		}
+		return true
+	})
+	if err != nil {
+		return moved, err
 	}
 
-	return nil
+	if err := m.awsClient.DeleteObject(ctx, m.env.S3.Bucket, m.env.GetDeploymentHistoryKey()); err != nil {
+		return moved, fmt.Errorf("failed to remove legacy deployment history: %w", err)
+	}
+	return moved, nil
 }
@@ -0,0 +1,31 @@
+package deployment
+
+import "testing"
+
+func TestUpdateLatestKeepsActiveDeploymentOnFailedApply(t *testing.T) {
+	head := &Head{}
+
+	succeeded := &Record{ID: "01", Command: CommandApply, Status: StatusInProgress}
+	updateLatest(head, succeeded)
+	succeeded.Status = StatusSuccess
+	if !updateLatest(head, succeeded) {
+		t.Fatal("a successful apply should change the head")
+	}
+
+	failed := &Record{ID: "02", Command: CommandApply, Status: StatusInProgress}
+	updateLatest(head, failed)
+	failed.Status = StatusFailure
+	if !updateLatest(head, failed) {
+		t.Error("finishing a run in progress should change the head")
+	}
+
+	if len(head.InProgress) != 0 {
+		t.Errorf("in progress = %v, want none", head.InProgress)
+	}
+	if head.LatestDeployment == nil || head.LatestDeployment.Deployment.ID != succeeded.ID {
+		t.Fatalf("latest deployment = %+v, want %s", head.LatestDeployment, succeeded.ID)
+	}
+	if head.LatestDeployment.Status != StatusActive {
+		t.Errorf("latest status = %s, want %s", head.LatestDeployment.Status, StatusActive)
+	}
+}
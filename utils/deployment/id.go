@@ -0,0 +1,82 @@
+package deployment
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+)
+
+// NewID returns a new ULID for a record started at t; IDs sort by time
+func NewID(t time.Time) string {
+	return ulid.MustNew(ulid.Timestamp(t), ulid.DefaultEntropy()).String()
+}
+
+// idTimeRange returns the times at which records with IDs starting with id were created.
+// The first 10 characters of a ULID are the time in milliseconds.
+func idTimeRange(id string) (time.Time, time.Time, bool) {
+	const timeSize = 10
+	prefix := strings.ToUpper(id)
+	if len(prefix) > timeSize {
+		prefix = prefix[:timeSize]
+	}
+	random := strings.Repeat("0", ulid.EncodedSize-timeSize)
+
+	lower, err := ulid.Parse(prefix + strings.Repeat("0", timeSize-len(prefix)) + random)
+	if err != nil {
+		return time.Time{}, time.Time{}, false
+	}
+	upper, err := ulid.Parse(prefix + strings.Repeat("Z", timeSize-len(prefix)) + random)
+	if err != nil {
+		return ulid.Time(lower.Time()), time.Time{}, true
+	}
+	return ulid.Time(lower.Time()), ulid.Time(upper.Time()).Add(time.Millisecond), true
+}
+
+// legacyID derives a stable ID for records written before IDs were introduced
+func legacyID(r *Record) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s|%s|%s|%s",
+		r.Timestamp.UTC().Format(time.RFC3339Nano), r.Command, r.VersionID, r.DeployedBy)))
+
+	var id ulid.ULID
+	_ = id.SetTime(ulid.Timestamp(r.Timestamp))
+	_ = id.SetEntropy(sum[:10])
+	return id.String()
+}
+
+// IsID reports whether s looks like a (possibly abbreviated) deployment ID
+func IsID(s string) bool {
+	if s == "" || len(s) > ulid.EncodedSize {
+		return false
+	}
+	for _, c := range strings.ToUpper(s) {
+		if !strings.ContainsRune(ulid.Encoding, c) {
+			return false
+		}
+	}
+	return true
+}
+
+// FindRecord returns the record whose ID is id or starts with it
+func FindRecord(records []Record, id string) (*Record, error) {
+	id = strings.ToUpper(id)
+
+	var found *Record
+	for i, r := range records {
+		if r.ID == id {
+			return &records[i], nil
+		}
+		if strings.HasPrefix(r.ID, id) {
+			if found != nil {
+				return nil, fmt.Errorf("deployment ID %s is ambiguous", id)
+			}
+			found = &records[i]
+		}
+	}
+	if found == nil {
+		return nil, fmt.Errorf("deployment %s not found", id)
+	}
+	return found, nil
+}
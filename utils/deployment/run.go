@@ -0,0 +1,55 @@
+package deployment
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"time"
+
+	"tfvarenv/utils/interrupt"
+)
+
+// Start saves the record as in progress before terraform starts, with the process that runs it.
+// If the record cannot be saved, Finish adds it when the run ends.
+func Start(ctx context.Context, manager Manager, record *Record) error {
+	record.Timestamp = time.Now()
+	record.Status = StatusInProgress
+	if record.Parameters == nil {
+		record.Parameters = make(map[string]string)
+	}
+	record.Parameters["PID"] = strconv.Itoa(os.Getpid())
+	if host, err := os.Hostname(); err == nil {
+		record.Parameters["Host"] = host
+	}
+
+	if err := manager.AddRecord(ctx, record); err != nil {
+		record.Status = ""
+		return err
+	}
+	return nil
+}
+
+// Finish records the result of a run started with Start. A failure after an interrupt
+// is recorded as interrupted.
+func Finish(ctx context.Context, manager Manager, record *Record, runErr error) error {
+	started := record.Status == StatusInProgress
+	switch {
+	case runErr == nil:
+		record.Status = StatusSuccess
+	case interrupt.IsInterrupted(ctx):
+		record.Status = StatusInterrupted
+	default:
+		record.Status = StatusFailure
+	}
+	if runErr != nil {
+		record.ErrorMessage = runErr.Error()
+	}
+	record.Duration = time.Since(record.Timestamp)
+
+	// 中断後も記録できるようにキャンセルを引き継がない
+	ctx = context.WithoutCancel(ctx)
+	if started {
+		return manager.UpdateRecord(ctx, record)
+	}
+	return manager.AddRecord(ctx, record)
+}
@@ -0,0 +1,421 @@
+package deployment
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+
+	"tfvarenv/utils/aws"
+)
+
+const (
+	// HeadFormatVersion is the format of head.json
+	HeadFormatVersion = "2.0"
+
+	headFile      = "head.json"
+	recordsDir    = "records/"
+	recordSuffix  = ".json"
+	queryBatch    = 32
+	downloadLimit = 8
+	// headRetries bounds the attempts to update head.json while other runs update it
+	headRetries = 5
+)
+
+// entry is a record found by listing, downloaded on demand
+type entry struct {
+	id     string
+	key    string
+	at     time.Time
+	record *Record
+}
+
+func (m *manager) headKey() string {
+	return m.env.GetDeploymentPrefix() + headFile
+}
+
+// recordKey partitions the records by UTC date: records/2006/01/02/<ID>.json
+func (m *manager) recordKey(record *Record) string {
+	return m.env.GetDeploymentPrefix() + recordsDir +
+		record.Timestamp.UTC().Format("2006/01/02/") + record.ID + recordSuffix
+}
+
+func (m *manager) putRecord(ctx context.Context, record *Record) error {
+	return m.putJSON(ctx, &aws.UploadInput{Key: m.recordKey(record)}, record)
+}
+
+// getHead returns the head pointer. Without one, it is derived from the legacy history file.
+func (m *manager) getHead(ctx context.Context) (*Head, error) {
+	head, _, err := m.loadHead(ctx)
+	return head, err
+}
+
+// loadHead returns the head pointer and the ETag of head.json, which is empty if it does not exist yet
+func (m *manager) loadHead(ctx context.Context) (*Head, string, error) {
+	var head Head
+	etag, found, err := m.getJSON(ctx, m.headKey(), &head)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get deployment head: %w", err)
+	}
+	if found {
+		return &head, etag, nil
+	}
+
+	head = Head{FormatVersion: HeadFormatVersion, Environment: m.env.Name}
+	legacy, err := m.getLegacyHistory(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+	if legacy != nil {
+		head.LatestDeployment = legacy.LatestDeployment
+		for _, d := range legacy.Deployments {
+			if d.Status == StatusInProgress {
+				head.InProgress = append(head.InProgress, d.ID)
+			}
+		}
+	}
+	return &head, "", nil
+}
+
+// saveHead writes the head pointer only if head.json still has the ETag it was read with
+func (m *manager) saveHead(ctx context.Context, head *Head, etag string) error {
+	head.FormatVersion = HeadFormatVersion
+	head.Environment = m.env.Name
+
+	input := &aws.UploadInput{Key: m.headKey(), IfMatch: etag}
+	if etag == "" {
+		input.IfNoneMatch = "*"
+	}
+	return m.putJSON(ctx, input, head)
+}
+
+// updateHead applies the change to the head pointer and saves it if anything changed.
+// If another run saved the head in the meantime, the change is applied again to its head.
+func (m *manager) updateHead(ctx context.Context, change func(*Head) bool) error {
+	for attempt := 0; attempt < headRetries; attempt++ {
+		head, etag, err := m.loadHead(ctx)
+		if err != nil {
+			return err
+		}
+		if !change(head) {
+			return nil
+		}
+
+		err = m.saveHead(ctx, head, etag)
+		if err == nil {
+			return nil
+		}
+		if !aws.IsPreconditionFailed(err) {
+			return fmt.Errorf("failed to save deployment head: %w", err)
+		}
+	}
+	return fmt.Errorf("failed to save deployment head: it was changed concurrently %d times", headRetries)
+}
+
+// getLegacyHistory reads the single-file history written before records were stored
+// individually. It returns nil if there is none.
+func (m *manager) getLegacyHistory(ctx context.Context) (*History, error) {
+	var history History
+	_, found, err := m.getJSON(ctx, m.env.GetDeploymentHistoryKey(), &history)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get deployment history: %w", err)
+	}
+	if !found {
+		return nil, nil
+	}
+
+	// IDのない古い記録には記録内容から決まるIDを付ける
+	for i := range history.Deployments {
+		if history.Deployments[i].ID == "" {
+			history.Deployments[i].ID = legacyID(&history.Deployments[i])
+		}
+	}
+	if latest := history.LatestDeployment; latest != nil && latest.Deployment != nil && latest.Deployment.ID == "" {
+		latest.Deployment.ID = legacyID(latest.Deployment)
+	}
+	return &history, nil
+}
+
+// partition is a month of records. Its objects are listed only when it is reached.
+type partition struct {
+	month  time.Time
+	prefix string
+	// legacy are the records of the month still in the legacy history file
+	legacy []entry
+}
+
+// listPartitions returns the monthly partitions that may hold records between since and before,
+// newest first. For ranges longer than a year, the months are found by listing the year and
+// month prefixes rather than every record. Records still in the legacy history file are sorted into their month.
+func (m *manager) listPartitions(ctx context.Context, since, before time.Time) ([]partition, error) {
+	base := m.env.GetDeploymentPrefix() + recordsDir
+	last := time.Now()
+	if !before.IsZero() && before.Before(last) {
+		last = before
+	}
+	last = monthStart(last)
+
+	first := monthStart(since)
+	var months []time.Time
+	if !since.IsZero() && !first.AddDate(1, 0, 0).Before(last) {
+		for month := first; !month.After(last); month = month.AddDate(0, 1, 0) {
+			months = append(months, month)
+		}
+	} else {
+		years, err := m.awsClient.ListPrefixes(ctx, m.env.S3.Bucket, base)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list deployment records: %w", err)
+		}
+		for _, year := range years {
+			y, err := time.Parse("2006/", strings.TrimPrefix(year, base))
+			if err != nil || y.After(last) || y.AddDate(1, 0, 0).Before(first) {
+				continue
+			}
+			prefixes, err := m.awsClient.ListPrefixes(ctx, m.env.S3.Bucket, year)
+			if err != nil {
+				return nil, fmt.Errorf("failed to list deployment records: %w", err)
+			}
+			for _, prefix := range prefixes {
+				month, err := time.Parse("2006/01/", strings.TrimPrefix(prefix, base))
+				if err != nil || month.After(last) || month.Before(first) {
+					continue
+				}
+				months = append(months, month)
+			}
+		}
+	}
+
+	partitions := make(map[string]*partition)
+	for _, month := range months {
+		prefix := base + month.Format("2006/01/")
+		partitions[prefix] = &partition{month: month, prefix: prefix}
+	}
+
+	legacy, err := m.getLegacyHistory(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if legacy != nil {
+		for i, d := range legacy.Deployments {
+			if !inRange(d.Timestamp, since, before) {
+				continue
+			}
+			month := monthStart(d.Timestamp)
+			prefix := base + month.Format("2006/01/")
+			p, ok := partitions[prefix]
+			if !ok {
+				p = &partition{month: month, prefix: prefix}
+				partitions[prefix] = p
+			}
+			p.legacy = append(p.legacy, entry{id: d.ID, at: d.Timestamp, record: &legacy.Deployments[i]})
+		}
+	}
+
+	result := make([]partition, 0, len(partitions))
+	for _, p := range partitions {
+		result = append(result, *p)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].month.After(result[j].month)
+	})
+	return result, nil
+}
+
+// listPartition returns the records of the partition between since and before, newest first
+func (m *manager) listPartition(ctx context.Context, p *partition, since, before time.Time) ([]entry, error) {
+	objects, err := m.awsClient.ListObjects(ctx, m.env.S3.Bucket, p.prefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deployment records: %w", err)
+	}
+
+	// ULIDはミリ秒精度なので範囲の判定に余裕を持たせる
+	lower := since
+	if !lower.IsZero() {
+		lower = lower.Add(-time.Millisecond)
+	}
+
+	var entries []entry
+	seen := make(map[string]bool)
+	for _, obj := range objects {
+		id := strings.TrimSuffix(path.Base(obj.Key), recordSuffix)
+		parsed, err := ulid.Parse(id)
+		if err != nil {
+			continue
+		}
+		at := ulid.Time(parsed.Time())
+		if !inRange(at, lower, before) {
+			continue
+		}
+		seen[id] = true
+		entries = append(entries, entry{id: id, key: obj.Key, at: at})
+	}
+	for _, e := range p.legacy {
+		if !seen[e.id] {
+			entries = append(entries, e)
+		}
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].at.Equal(entries[j].at) {
+			return entries[i].id > entries[j].id
+		}
+		return entries[i].at.After(entries[j].at)
+	})
+	return entries, nil
+}
+
+// listEntries returns the records that may lie between since and before, newest first
+func (m *manager) listEntries(ctx context.Context, since, before time.Time) ([]entry, error) {
+	partitions, err := m.listPartitions(ctx, since, before)
+	if err != nil {
+		return nil, err
+	}
+	var entries []entry
+	for i := range partitions {
+		listed, err := m.listPartition(ctx, &partitions[i], since, before)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, listed...)
+	}
+	return entries, nil
+}
+
+// query returns the matching records between since and before (inclusive), newest first.
+// The partitions are walked newest first and only as many records are downloaded as needed
+// to reach the limit.
+func (m *manager) query(ctx context.Context, since, before time.Time, limit int, match func(*Record) bool) ([]Record, error) {
+	partitions, err := m.listPartitions(ctx, since, before)
+	if err != nil {
+		return nil, err
+	}
+
+	batchSize := queryBatch
+	if limit > 0 && limit < batchSize {
+		batchSize = limit
+	}
+
+	result := make([]Record, 0)
+	for i := range partitions {
+		entries, err := m.listPartition(ctx, &partitions[i], since, before)
+		if err != nil {
+			return nil, err
+		}
+
+		for start := 0; start < len(entries); start += batchSize {
+			end := start + batchSize
+			if end > len(entries) {
+				end = len(entries)
+			}
+			batch := entries[start:end]
+			if err := m.loadEntries(ctx, batch); err != nil {
+				return nil, err
+			}
+
+			for _, e := range batch {
+				if !inRange(e.record.Timestamp, since, before) || (match != nil && !match(e.record)) {
+					continue
+				}
+				result = append(result, *e.record)
+				if limit > 0 && len(result) == limit {
+					return result, nil
+				}
+			}
+		}
+	}
+	return result, nil
+}
+
+// loadEntries downloads the records of the entries concurrently
+func (m *manager) loadEntries(ctx context.Context, entries []entry) error {
+	errs := make([]error, len(entries))
+	sem := make(chan struct{}, downloadLimit)
+	var wg sync.WaitGroup
+	for i := range entries {
+		if entries[i].record != nil {
+			continue
+		}
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(e *entry, errp *error) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			var record Record
+			_, found, err := m.getJSON(ctx, e.key, &record)
+			if err == nil && !found {
+				err = fmt.Errorf("deployment record %s not found", e.id)
+			}
+			if err != nil {
+				*errp = err
+				return
+			}
+			e.record = &record
+		}(&entries[i], &errs[i])
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getJSON decodes the object into v and returns its ETag. It returns false if the object does not exist;
+// other download errors are returned.
+func (m *manager) getJSON(ctx context.Context, key string, v interface{}) (string, bool, error) {
+	output, err := m.awsClient.DownloadFile(ctx, &aws.DownloadInput{
+		Bucket: m.env.S3.Bucket,
+		Key:    key,
+	})
+	if aws.IsNotFound(err) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("failed to download %s: %w", key, err)
+	}
+	if err := json.Unmarshal(output.Content, v); err != nil {
+		return "", false, fmt.Errorf("failed to decode %s: %w", key, err)
+	}
+	return output.ETag, true, nil
+}
+
+// putJSON uploads v as the content of the input, which gives the key and any upload condition
+func (m *manager) putJSON(ctx context.Context, input *aws.UploadInput, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", path.Base(input.Key), err)
+	}
+
+	input.Bucket = m.env.S3.Bucket
+	input.Content = data
+	input.ContentType = "application/json"
+	if _, err := m.awsClient.UploadFile(ctx, input); err != nil {
+		return fmt.Errorf("failed to upload %s: %w", input.Key, err)
+	}
+	return nil
+}
+
+// inRange reports whether t lies between since and before (inclusive); zero bounds are open
+func inRange(t, since, before time.Time) bool {
+	if !since.IsZero() && t.Before(since) {
+		return false
+	}
+	if !before.IsZero() && t.After(before) {
+		return false
+	}
+	return true
+}
+
+func monthStart(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
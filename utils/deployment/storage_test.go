@@ -0,0 +1,138 @@
+package deployment
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+
+	"tfvarenv/config"
+	"tfvarenv/utils/aws"
+)
+
+// fakeS3 keeps objects in memory and records which prefixes were listed
+type fakeS3 struct {
+	aws.Client
+	objects map[string][]byte
+	listed  []string
+}
+
+func (f *fakeS3) DownloadFile(ctx context.Context, input *aws.DownloadInput) (*aws.DownloadOutput, error) {
+	content, ok := f.objects[input.Key]
+	if !ok {
+		return nil, &types.NoSuchKey{}
+	}
+	return &aws.DownloadOutput{Content: content}, nil
+}
+
+func (f *fakeS3) ListObjects(ctx context.Context, bucket, prefix string) ([]aws.ObjectInfo, error) {
+	f.listed = append(f.listed, prefix)
+	var objects []aws.ObjectInfo
+	for key := range f.objects {
+		if strings.HasPrefix(key, prefix) {
+			objects = append(objects, aws.ObjectInfo{Key: key})
+		}
+	}
+	return objects, nil
+}
+
+func (f *fakeS3) ListPrefixes(ctx context.Context, bucket, prefix string) ([]string, error) {
+	seen := make(map[string]bool)
+	var prefixes []string
+	for key := range f.objects {
+		rest, ok := strings.CutPrefix(key, prefix)
+		if !ok {
+			continue
+		}
+		if i := strings.Index(rest, "/"); i >= 0 && !seen[rest[:i]] {
+			seen[rest[:i]] = true
+			prefixes = append(prefixes, prefix+rest[:i+1])
+		}
+	}
+	sort.Strings(prefixes)
+	return prefixes, nil
+}
+
+func newTestManager(t *testing.T, timestamps ...string) (*manager, *fakeS3, []*Record) {
+	t.Helper()
+	client := &fakeS3{objects: make(map[string][]byte)}
+	m := &manager{
+		awsClient: client,
+		env:       &config.Environment{Name: "dev", S3: config.EnvironmentS3Config{Bucket: "bucket", Prefix: "app"}},
+	}
+
+	var records []*Record
+	for _, ts := range timestamps {
+		at, err := time.Parse(time.RFC3339, ts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		record := &Record{
+			ID:        NewID(at),
+			Timestamp: at,
+			VersionID: "v-" + ts,
+			Command:   CommandApply,
+			Status:    StatusSuccess,
+		}
+		data, err := json.Marshal(record)
+		if err != nil {
+			t.Fatal(err)
+		}
+		client.objects[m.recordKey(record)] = data
+		records = append(records, record)
+	}
+	return m, client, records
+}
+
+func TestQueryStopsAtNewestPartition(t *testing.T) {
+	m, client, records := newTestManager(t, "2023-01-10T00:00:00Z", "2024-06-15T00:00:00Z", "2025-03-01T00:00:00Z")
+
+	found, err := m.QueryDeployments(context.Background(), QueryOptions{Limit: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 1 || found[0].ID != records[2].ID {
+		t.Fatalf("found %+v, want %s", found, records[2].ID)
+	}
+	if want := []string{"app/.deployments/dev/records/2025/03/"}; !slices.Equal(client.listed, want) {
+		t.Errorf("listed %v, want %v", client.listed, want)
+	}
+}
+
+func TestGetRecordListsOnlyTheIDTimeRange(t *testing.T) {
+	m, client, records := newTestManager(t, "2023-01-10T00:00:00Z", "2024-06-15T00:00:00Z", "2025-03-01T00:00:00Z")
+
+	record, err := m.GetRecord(context.Background(), records[1].ID[:8])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if record.ID != records[1].ID {
+		t.Errorf("got %s, want %s", record.ID, records[1].ID)
+	}
+	if want := []string{"app/.deployments/dev/records/2024/06/"}; !slices.Equal(client.listed, want) {
+		t.Errorf("listed %v, want %v", client.listed, want)
+	}
+}
+
+func TestGetActiveDeploymentAtWalksBackFromTheTime(t *testing.T) {
+	m, client, records := newTestManager(t, "2023-01-10T00:00:00Z", "2024-06-15T00:00:00Z", "2025-03-01T00:00:00Z")
+
+	at := time.Date(2024, 8, 1, 0, 0, 0, 0, time.UTC)
+	record, err := m.GetActiveDeploymentAt(context.Background(), at)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if record == nil || record.ID != records[1].ID {
+		t.Fatalf("got %+v, want %s", record, records[1].ID)
+	}
+	for _, prefix := range client.listed {
+		if strings.HasSuffix(prefix, "2023/01/") || strings.HasSuffix(prefix, "2025/03/") {
+			t.Errorf("listed %s outside the walk", prefix)
+		}
+	}
+}
@@ -2,10 +2,15 @@ package deployment
 
 import (
 	"time"
+
+	"tfvarenv/utils/identity"
+	"tfvarenv/utils/plan"
 )
 
 // Record represents a single deployment record
 type Record struct {
+	// ID is a ULID assigned when the record is added
+	ID           string            `json:"id,omitempty"`
 	Timestamp    time.Time         `json:"timestamp"`
 	VersionID    string            `json:"version_id"`
 	DeployedBy   string            `json:"deployed_by"`
@@ -15,9 +20,28 @@ type Record struct {
 	Parameters   map[string]string `json:"parameters,omitempty"`
 	Duration     time.Duration     `json:"duration,omitempty"`
 	ErrorMessage string            `json:"error_message,omitempty"`
+	// ExitCode is the exit code of terraform, if it ran
+	ExitCode *int `json:"exit_code,omitempty"`
+	// Identity describes who ran the command: caller ARN, git state and CI run
+	Identity *identity.Identity `json:"identity,omitempty"`
+	// Changes are the resource counts of the plan that was applied
+	Changes *plan.Summary `json:"changes,omitempty"`
+	// TerraformBinary and TerraformVersion identify the executable used for the deployment
+	TerraformBinary  string `json:"terraform_binary,omitempty"`
+	TerraformVersion string `json:"terraform_version,omitempty"`
+	// BreakGlass is set when the run overrode a freeze window
+	BreakGlass *BreakGlass `json:"break_glass,omitempty"`
+	// PlanCheck is the result of the plan safety checks of an apply
+	PlanCheck *plan.CheckResult `json:"plan_check,omitempty"`
+}
+
+// BreakGlass records why a freeze window was overridden
+type BreakGlass struct {
+	Reason       string `json:"reason"`
+	FreezeWindow string `json:"freeze_window"`
 }
 
-// History represents the deployment history file structure
+// History is the whole deployment history of an environment
 type History struct {
 	FormatVersion    string      `json:"format_version"`
 	Environment      string      `json:"environment"`
@@ -25,6 +49,15 @@ type History struct {
 	Deployments      []Record    `json:"deployments"`
 }
 
+// Head is the small pointer object stored next to the deployment records
+type Head struct {
+	FormatVersion    string      `json:"format_version"`
+	Environment      string      `json:"environment"`
+	LatestDeployment *LatestInfo `json:"latest_deployment,omitempty"`
+	// InProgress are the IDs of records that have not finished yet
+	InProgress []string `json:"in_progress,omitempty"`
+}
+
 // LatestInfo represents the most recent deployment information
 type LatestInfo struct {
 	Deployment   *Record   `json:"deployment,omitempty"`
@@ -38,6 +71,7 @@ type QueryOptions struct {
 	Before     time.Time
 	Limit      int
 	Status     string
+	Command    string
 	DeployedBy string
 	VersionID  string
 }
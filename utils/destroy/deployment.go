@@ -3,23 +3,57 @@ package destroy
 import (
 	"context"
 	"fmt"
-	"os"
 	"time"
 
 	"tfvarenv/utils/deployment"
 )
 
-func (m *Manager) recordDeployment(ctx context.Context, opts *Options, versionInfo *VersionInfo, status string, err error) {
+// startDeployment completes the record (break-glass, identity and changes are set by the caller)
+// and saves it as in progress before terraform starts
+func (m *Manager) startDeployment(ctx context.Context, opts *Options, versionInfo *VersionInfo, record *deployment.Record) {
+	record.VersionID = versionInfo.Version.VersionID
+	record.DeployedBy = record.Identity.Deployer
+	record.Command = deployment.CommandDestroy
+	record.Environment = opts.Environment.Name
+	record.Parameters = map[string]string{
+		"AutoApprove": fmt.Sprintf("%v", opts.AutoApprove),
+	}
+	if lifted := opts.Environment.Deployment.ProtectionLifted; lifted != nil {
+		record.Parameters["ProtectionLiftedBy"] = lifted.By
+		record.Parameters["ProtectionLiftedReason"] = lifted.Reason
+	}
+	if binary, err := m.tfRunner.ResolveBinary(ctx, opts.Environment); err == nil {
+		record.TerraformBinary = binary.Binary
+		record.TerraformVersion = binary.Version
+	}
+
+	deploymentManager := deployment.NewManager(m.awsClient, opts.Environment)
+	if err := deployment.Start(ctx, deploymentManager, record); err != nil {
+		fmt.Printf("Warning: Failed to record destroy start: %v\n", err)
+	}
+}
+
+// recordDeployment completes the in-progress record with the result of terraform
+func (m *Manager) recordDeployment(ctx context.Context, opts *Options, record *deployment.Record, err error) {
 	deploymentManager := deployment.NewManager(m.awsClient, opts.Environment)
+	if recordErr := deployment.Finish(ctx, deploymentManager, record, err); recordErr != nil {
+		fmt.Printf("Warning: Failed to record destroy: %v\n", recordErr)
+	}
 
-	if status == "success" {
-		if err := deploymentManager.MarkAsDestroyed(ctx); err != nil {
+	switch record.Status {
+	case deployment.StatusSuccess:
+		if err := deploymentManager.MarkAsDestroyed(context.WithoutCancel(ctx)); err != nil {
 			fmt.Printf("Warning: Failed to mark environment as destroyed: %v\n", err)
 			return
 		}
 
 		fmt.Printf("\nEnvironment marked as destroyed:\n")
 		fmt.Printf("  Time: %s\n", time.Now().Format("2006-01-02 15:04:05"))
-		fmt.Printf("  By: %s\n", os.Getenv("USER"))
+		fmt.Printf("  By: %s\n", record.DeployedBy)
+		if record.BreakGlass != nil {
+			fmt.Printf("  Break-Glass: %s\n", record.BreakGlass.Reason)
+		}
+	case deployment.StatusInterrupted:
+		fmt.Printf("\nDestroy interrupted; some resources may remain. Check with: tfvarenv status %s\n", opts.Environment.Name)
 	}
 }
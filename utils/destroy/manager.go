@@ -3,37 +3,91 @@ package destroy
 import (
 	"context"
 	"fmt"
+	"os"
+	"time"
 
 	"tfvarenv/utils/aws"
 	"tfvarenv/utils/deployment"
 	"tfvarenv/utils/file"
+	"tfvarenv/utils/freeze"
+	"tfvarenv/utils/identity"
+	"tfvarenv/utils/policy"
 	"tfvarenv/utils/terraform"
 	"tfvarenv/utils/version"
 )
 
 type Manager struct {
-	awsClient aws.Client
-	fileUtils file.Utils
-	tfRunner  terraform.Runner
+	awsClient  aws.Client
+	fileUtils  file.Utils
+	tfRunner   terraform.Runner
+	idResolver identity.Resolver
+	lookupEnv  policy.EnvironmentLookup
 }
 
-func NewManager(awsClient aws.Client, fileUtils file.Utils, tfRunner terraform.Runner) *Manager {
+func NewManager(awsClient aws.Client, fileUtils file.Utils, tfRunner terraform.Runner, idResolver identity.Resolver, lookupEnv policy.EnvironmentLookup) *Manager {
 	return &Manager{
-		awsClient: awsClient,
-		fileUtils: fileUtils,
-		tfRunner:  tfRunner,
+		awsClient:  awsClient,
+		fileUtils:  fileUtils,
+		tfRunner:   tfRunner,
+		idResolver: idResolver,
+		lookupEnv:  lookupEnv,
 	}
 }
 
 func (m *Manager) Execute(ctx context.Context, opts *Options) error {
+	if opts.Environment.Deployment.Protected {
+		return fmt.Errorf("environment %s is protected and cannot be destroyed; lift the protection first: tfvarenv protect %s --lift --reason \"...\"",
+			opts.Environment.Name, opts.Environment.Name)
+	}
+
+	if _, err := m.tfRunner.ResolveBinary(ctx, opts.Environment); err != nil {
+		return err
+	}
+	id, err := m.idResolver.Resolve(ctx, opts.Environment.GetWorkingDir())
+	if err != nil {
+		return fmt.Errorf("failed to resolve identity: %w", err)
+	}
+
+	// Check the initialized backend so that another environment's state is never destroyed
+	if err := m.tfRunner.EnsureBackend(ctx, opts.Environment, opts.Reinit); err != nil {
+		return err
+	}
+
 	// Get version information
 	versionInfo, err := m.getVersionToDestroy(ctx, opts)
 	if err != nil {
 		return err
 	}
 
+	policyManager := policy.NewManager(m.awsClient, m.fileUtils, m.lookupEnv)
+	if err := policyManager.Enforce(ctx, &policy.Input{
+		Environment: opts.Environment,
+		Command:     deployment.CommandDestroy,
+		Version:     versionInfo.Version,
+		AutoApprove: opts.AutoApprove,
+	}, opts.Explain); err != nil {
+		return err
+	}
+
+	breakGlass, err := freeze.Enforce(opts.Environment, opts.BreakGlass, time.Now())
+	if err != nil {
+		return err
+	}
+
+	// Plan the destruction and show what would be destroyed
+	defer os.RemoveAll(tmpDir(opts))
+	planFile, destroyPlan, err := m.runDestroyPlan(ctx, opts, versionInfo)
+	if err != nil {
+		return err
+	}
+
 	// Display destroy plan
 	m.displayDestroyPlan(opts, versionInfo)
+	m.displayInventory(destroyPlan)
+	if len(destroyPlan.Destructive()) == 0 {
+		fmt.Println("\nNo resources to destroy")
+		return nil
+	}
 
 	// Get confirmation
 	if !opts.AutoApprove {
@@ -43,14 +97,23 @@ func (m *Manager) Execute(ctx context.Context, opts *Options) error {
 	}
 
 	// Run terraform destroy
-	result, err := m.runTerraformDestroy(ctx, opts, versionInfo)
+	record := &deployment.Record{
+		BreakGlass: breakGlass,
+		Identity:   id,
+		Changes:    destroyPlan.Summary(),
+	}
+	m.startDeployment(ctx, opts, versionInfo, record)
+	result, err := m.runTerraformDestroy(ctx, opts, planFile)
+	if result != nil {
+		record.ExitCode = &result.ExitCode
+	}
 	if err != nil {
-		m.recordDeployment(ctx, opts, versionInfo, "failed", err)
+		m.recordDeployment(ctx, opts, record, err)
 		return err
 	}
 
 	// Record successful destruction
-	m.recordDeployment(ctx, opts, versionInfo, "success", nil)
+	m.recordDeployment(ctx, opts, record, nil)
 
 	// Display result
 	m.displayResult(result, versionInfo)
@@ -102,6 +165,10 @@ func (m *Manager) displayDestroyPlan(opts *Options, versionInfo *VersionInfo) {
 	if versionInfo.Version.Description != "" {
 		fmt.Printf("  Version Description: %s\n", versionInfo.Version.Description)
 	}
+	if lifted := opts.Environment.Deployment.ProtectionLifted; lifted != nil {
+		fmt.Printf("  Protection Lifted: %s by %s (%s)\n",
+			lifted.At.Format("2006-01-02 15:04:05"), lifted.By, lifted.Reason)
+	}
 	fmt.Println("\nThis operation will DESTROY all resources managed by Terraform!")
 }
 
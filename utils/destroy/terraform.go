@@ -3,27 +3,25 @@ package destroy
 import (
 	"context"
 	"fmt"
-	"os"
 	"path/filepath"
+	"sort"
 
 	"tfvarenv/utils/aws"
+	"tfvarenv/utils/file"
+	"tfvarenv/utils/plan"
 	"tfvarenv/utils/terraform"
 )
 
-func (m *Manager) runTerraformDestroy(ctx context.Context, opts *Options, versionInfo *VersionInfo) (*terraform.ExecutionResult, error) {
-	tfOpts := &terraform.DestroyOptions{
-		Environment: opts.Environment,
-		AutoApprove: opts.AutoApprove,
-		Options:     opts.TerraformOpts,
-	}
+// rootModule is the label of resources outside of modules
+const rootModule = "(root module)"
 
-	// Prepare tfvars file
-	tmpDir := filepath.Join(".tmp", opts.Environment.Name)
-	if err := m.fileUtils.EnsureDirectory(tmpDir); err != nil {
-		return nil, fmt.Errorf("failed to create temporary directory: %w", err)
-	}
-	defer os.RemoveAll(tmpDir)
+// tmpDir holds the tfvars file and the saved destroy plan
+func tmpDir(opts *Options) string {
+	return filepath.Join(".tmp", opts.Environment.Name)
+}
 
+// runDestroyPlan saves a destroy plan with the tfvars of the version and reads it back
+func (m *Manager) runDestroyPlan(ctx context.Context, opts *Options, versionInfo *VersionInfo) (string, *plan.Plan, error) {
 	// Download tfvars file
 	input := &aws.DownloadInput{
 		Bucket:    opts.Environment.S3.Bucket,
@@ -32,17 +30,93 @@ func (m *Manager) runTerraformDestroy(ctx context.Context, opts *Options, versio
 	}
 	output, err := m.awsClient.DownloadFile(ctx, input)
 	if err != nil {
-		return nil, fmt.Errorf("failed to download tfvars: %w", err)
+		return "", nil, fmt.Errorf("failed to download tfvars: %w", err)
 	}
 
-	tmpVarFile := filepath.Join(tmpDir, "terraform.tfvars")
-	if err := m.fileUtils.WriteFile(tmpVarFile, output.Content, nil); err != nil {
-		return nil, fmt.Errorf("failed to write temporary tfvars file: %w", err)
+	tmpVarFile := filepath.Join(tmpDir(opts), "terraform.tfvars")
+	if err := m.fileUtils.WriteFile(tmpVarFile, output.Content, &file.Options{
+		CreateDirs: true,
+		Overwrite:  true,
+	}); err != nil {
+		return "", nil, fmt.Errorf("failed to write temporary tfvars file: %w", err)
 	}
-	tfOpts.VarFile = tmpVarFile
 
-	// Run terraform destroy
-	return m.tfRunner.Destroy(ctx, tfOpts)
+	fmt.Printf("\nPlanning destruction of environment '%s'...\n", opts.Environment.Name)
+	planFile := filepath.Join(tmpDir(opts), "destroy.tfplan")
+	result, err := m.tfRunner.Plan(ctx, &terraform.PlanOptions{
+		Environment: opts.Environment,
+		VarFile:     tmpVarFile,
+		Options:     opts.TerraformOpts,
+		Out:         planFile,
+		Destroy:     true,
+		Reinit:      opts.Reinit,
+	})
+	if err != nil {
+		return "", nil, fmt.Errorf("terraform plan -destroy failed: %w", err)
+	}
+	if !result.Success {
+		return "", nil, fmt.Errorf("terraform plan -destroy failed: %s", result.ErrorOutput)
+	}
+
+	data, err := m.tfRunner.ShowPlanJSON(ctx, opts.Environment, planFile)
+	if err != nil {
+		return "", nil, err
+	}
+	p, err := plan.ParseJSON(data)
+	if err != nil {
+		return "", nil, err
+	}
+	return planFile, p, nil
+}
+
+// runTerraformDestroy applies the saved destroy plan, so exactly the listed resources are destroyed
+func (m *Manager) runTerraformDestroy(ctx context.Context, opts *Options, planFile string) (*terraform.ExecutionResult, error) {
+	return m.tfRunner.Apply(ctx, &terraform.ApplyOptions{
+		Environment: opts.Environment,
+		PlanFile:    planFile,
+		Reinit:      opts.Reinit,
+	})
+}
+
+// displayInventory lists the resources that would be destroyed, grouped by module
+func (m *Manager) displayInventory(p *plan.Plan) {
+	byModule := make(map[string][]plan.Change)
+	total := 0
+	for _, c := range p.Changes {
+		if c.Action != plan.ActionDelete {
+			continue
+		}
+		module := c.ModuleAddress
+		if module == "" {
+			module = rootModule
+		}
+		byModule[module] = append(byModule[module], c)
+		total++
+	}
+
+	fmt.Printf("\nResources to be destroyed (%d):\n", total)
+	modules := make([]string, 0, len(byModule))
+	for module := range byModule {
+		modules = append(modules, module)
+	}
+	sort.Slice(modules, func(i, j int) bool {
+		// ルートモジュールを先頭に表示する
+		if modules[i] == rootModule || modules[j] == rootModule {
+			return modules[i] == rootModule
+		}
+		return modules[i] < modules[j]
+	})
+
+	for _, module := range modules {
+		changes := byModule[module]
+		sort.Slice(changes, func(i, j int) bool {
+			return changes[i].Address < changes[j].Address
+		})
+		fmt.Printf("\n  %s (%d)\n", module, len(changes))
+		for _, c := range changes {
+			fmt.Printf("    %-50s %s\n", c.RelativeAddress(), c.Type)
+		}
+	}
 }
 
 func (m *Manager) displayResult(result *terraform.ExecutionResult, versionInfo *VersionInfo) {
@@ -13,6 +13,11 @@ type Options struct {
 	VersionID     string // Optional: specific version to use
 	AutoApprove   bool
 	TerraformOpts []string
+	Reinit        bool
+	// Explain shows the result of every deployment policy rule
+	Explain bool
+	// BreakGlass is the reason for destroying inside a freeze window
+	BreakGlass string
 }
 
 // VersionInfo contains version and deployment information
@@ -0,0 +1,163 @@
+package drift
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"tfvarenv/config"
+	"tfvarenv/utils/aws"
+	"tfvarenv/utils/deployment"
+	"tfvarenv/utils/file"
+	"tfvarenv/utils/plan"
+	"tfvarenv/utils/terraform"
+	"tfvarenv/utils/version"
+)
+
+// planExitChanges is the exit code of 'terraform plan -detailed-exitcode' when there are changes
+const planExitChanges = 2
+
+// Manager checks environments for drift
+type Manager interface {
+	Check(ctx context.Context, env *config.Environment) *Result
+}
+
+type manager struct {
+	awsClient aws.Client
+	fileUtils file.Utils
+	tfRunner  terraform.Runner
+}
+
+// NewManager creates a new drift manager
+func NewManager(awsClient aws.Client, fileUtils file.Utils, tfRunner terraform.Runner) Manager {
+	return &manager{
+		awsClient: awsClient,
+		fileUtils: fileUtils,
+		tfRunner:  tfRunner,
+	}
+}
+
+// Check plans the environment with its deployed version and compares the tfvars
+// with the uploaded versions and the local file
+func (m *manager) Check(ctx context.Context, env *config.Environment) *Result {
+	start := time.Now()
+	result := &Result{Environment: env.Name}
+	if err := m.check(ctx, env, result); err != nil {
+		result.Status = StatusError
+		result.Error = err.Error()
+	}
+	result.Duration = time.Since(start)
+	return result
+}
+
+func (m *manager) check(ctx context.Context, env *config.Environment, result *Result) error {
+	deploymentManager := deployment.NewManager(m.awsClient, env)
+	deployed, err := deploymentManager.GetActiveDeploymentAt(ctx, time.Now())
+	if err != nil {
+		return err
+	}
+	if deployed == nil {
+		result.Status = StatusNotDeployed
+		return nil
+	}
+	result.DeployedVersion = deployed.VersionID
+	result.DeployedAt = &deployed.Timestamp
+
+	versionManager := version.NewManager(m.awsClient, m.fileUtils, env)
+	deployedVersion, err := versionManager.GetVersion(ctx, deployed.VersionID)
+	if err != nil {
+		return fmt.Errorf("failed to get deployed version: %w", err)
+	}
+	if err := m.compareVersions(ctx, versionManager, env, deployedVersion, result); err != nil {
+		return err
+	}
+
+	// 最新のアップロードではなく、デプロイ済みのバージョンでプランする
+	if err := m.planDeployed(ctx, versionManager, env, deployedVersion, result); err != nil {
+		return err
+	}
+
+	switch {
+	case result.Drift:
+		result.Status = StatusDrift
+	case result.UndeployedVersions > 0 || result.LocalStatus == LocalModified:
+		result.Status = StatusOutOfSync
+	default:
+		result.Status = StatusInSync
+	}
+	return nil
+}
+
+// compareVersions counts the versions uploaded after the deployed one and compares the local file
+func (m *manager) compareVersions(ctx context.Context, versionManager version.Manager, env *config.Environment, deployed *version.Version, result *Result) error {
+	versions, err := versionManager.GetVersions(ctx, &version.QueryOptions{SortByDate: true})
+	if err != nil {
+		return fmt.Errorf("failed to get versions: %w", err)
+	}
+	for _, v := range versions {
+		if v.Timestamp.After(deployed.Timestamp) {
+			result.UndeployedVersions++
+		}
+	}
+	if len(versions) > 0 {
+		result.LatestVersion = versions[0].VersionID
+	}
+
+	exists, err := m.fileUtils.FileExists(env.Local.TFVarsPath)
+	if err != nil {
+		return fmt.Errorf("failed to check local file: %w", err)
+	}
+	if !exists {
+		result.LocalStatus = LocalMissing
+		return nil
+	}
+	hash, err := m.fileUtils.CalculateHash(env.Local.TFVarsPath, nil)
+	if err != nil {
+		return fmt.Errorf("failed to calculate local file hash: %w", err)
+	}
+	result.LocalStatus = LocalInSync
+	if hash != deployed.Hash {
+		result.LocalStatus = LocalModified
+	}
+	return nil
+}
+
+// planDeployed runs 'terraform plan -detailed-exitcode' with the deployed tfvars
+func (m *manager) planDeployed(ctx context.Context, versionManager version.Manager, env *config.Environment, deployed *version.Version, result *Result) error {
+	content, err := versionManager.GetVersionContent(ctx, deployed.VersionID)
+	if err != nil {
+		return fmt.Errorf("failed to download deployed version: %w", err)
+	}
+
+	tmpDir := filepath.Join(".tmp", env.Name, "drift")
+	defer os.RemoveAll(tmpDir)
+	varFile := filepath.Join(tmpDir, "terraform.tfvars")
+	if err := m.fileUtils.WriteFile(varFile, content, &file.Options{
+		CreateDirs: true,
+		Overwrite:  true,
+	}); err != nil {
+		return fmt.Errorf("failed to write temporary tfvars file: %w", err)
+	}
+
+	planResult, err := m.tfRunner.Plan(ctx, &terraform.PlanOptions{
+		Environment: env,
+		VarFile:     varFile,
+		NoColor:     true,
+		Quiet:       true,
+		Options:     []string{"-detailed-exitcode", "-input=false"},
+	})
+	if planResult == nil || (err != nil && planResult.ExitCode != planExitChanges) {
+		if planResult != nil && planResult.ErrorOutput != "" {
+			return fmt.Errorf("terraform plan failed: %s", planResult.ErrorOutput)
+		}
+		return fmt.Errorf("terraform plan failed: %w", err)
+	}
+
+	result.Drift = planResult.ExitCode == planExitChanges
+	if summary, err := plan.ParseSummary(planResult.Output); err == nil {
+		result.Changes = summary
+	}
+	return nil
+}
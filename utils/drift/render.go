@@ -0,0 +1,200 @@
+package drift
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"tfvarenv/utils/version"
+)
+
+// Render writes the report in the given format
+func Render(w io.Writer, r *Report, format string) error {
+	switch format {
+	case FormatTable:
+		return renderTable(w, r)
+	case FormatJSON:
+		return renderJSON(w, r)
+	case FormatJUnit:
+		return renderJUnit(w, r)
+	default:
+		return fmt.Errorf("unsupported format: %s (must be %s, %s or %s)", format, FormatTable, FormatJSON, FormatJUnit)
+	}
+}
+
+func renderTable(w io.Writer, r *Report) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ENVIRONMENT\tSTATUS\tDEPLOYED\tINFRASTRUCTURE\tUNDEPLOYED VERSIONS\tLOCAL FILE")
+	for _, result := range r.Results {
+		deployed := "-"
+		if result.DeployedVersion != "" {
+			deployed = version.ShortID(result.DeployedVersion)
+			if result.DeployedAt != nil {
+				deployed += " (" + result.DeployedAt.Format("2006-01-02 15:04") + ")"
+			}
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			result.Environment,
+			result.Status,
+			deployed,
+			describeInfrastructure(&result),
+			describeUndeployed(&result),
+			orDash(result.LocalStatus))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	for _, result := range r.Results {
+		if result.Error != "" {
+			fmt.Fprintf(w, "\n%s: %s\n", result.Environment, singleLine(result.Error))
+		}
+	}
+	return nil
+}
+
+func renderJSON(w io.Writer, r *Report) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal report: %w", err)
+	}
+	_, err = fmt.Fprintln(w, string(data))
+	return err
+}
+
+type junitSuite struct {
+	XMLName   xml.Name    `xml:"testsuite"`
+	Name      string      `xml:"name,attr"`
+	Tests     int         `xml:"tests,attr"`
+	Failures  int         `xml:"failures,attr"`
+	Errors    int         `xml:"errors,attr"`
+	Skipped   int         `xml:"skipped,attr"`
+	Timestamp string      `xml:"timestamp,attr"`
+	Cases     []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Error     *junitMessage `xml:"error,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr,omitempty"`
+	Text    string `xml:",chardata"`
+}
+
+// renderJUnit reports each environment as a test case: drift and out-of-sync tfvars are
+// failures, errors are errors and environments that are not deployed are skipped
+func renderJUnit(w io.Writer, r *Report) error {
+	suite := junitSuite{
+		Name:      "tfvarenv drift",
+		Tests:     len(r.Results),
+		Timestamp: r.CheckedAt.Format("2006-01-02T15:04:05"),
+	}
+	for _, result := range r.Results {
+		c := junitCase{
+			Name:      result.Environment,
+			ClassName: "tfvarenv.drift",
+			Time:      fmt.Sprintf("%.3f", result.Duration.Seconds()),
+		}
+		switch result.Status {
+		case StatusDrift, StatusOutOfSync:
+			suite.Failures++
+			c.Failure = &junitMessage{
+				Message: describeFailure(&result),
+				Type:    result.Status,
+				Text:    describeDetails(&result),
+			}
+		case StatusError:
+			suite.Errors++
+			c.Error = &junitMessage{Message: singleLine(result.Error), Text: result.Error}
+		case StatusNotDeployed:
+			suite.Skipped++
+			c.Skipped = &junitMessage{Message: "not deployed"}
+		}
+		suite.Cases = append(suite.Cases, c)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(suite); err != nil {
+		return fmt.Errorf("failed to encode report: %w", err)
+	}
+	_, err := fmt.Fprintln(w)
+	return err
+}
+
+func describeInfrastructure(result *Result) string {
+	switch {
+	case result.Status == StatusError || result.Status == StatusNotDeployed:
+		return "-"
+	case !result.Drift:
+		return "no drift"
+	case result.Changes != nil:
+		return "drift: " + result.Changes.String()
+	default:
+		return "drift"
+	}
+}
+
+func describeUndeployed(result *Result) string {
+	if result.Status == StatusError || result.Status == StatusNotDeployed {
+		return "-"
+	}
+	if result.UndeployedVersions == 0 {
+		return "0"
+	}
+	return fmt.Sprintf("%d (latest %s)", result.UndeployedVersions, version.ShortID(result.LatestVersion))
+}
+
+func describeFailure(result *Result) string {
+	var problems []string
+	if result.Drift {
+		problems = append(problems, describeInfrastructure(result))
+	}
+	if result.UndeployedVersions > 0 {
+		problems = append(problems, fmt.Sprintf("%d undeployed tfvars version(s)", result.UndeployedVersions))
+	}
+	if result.LocalStatus == LocalModified {
+		problems = append(problems, "local tfvars differ from the deployed version")
+	}
+	return strings.Join(problems, "; ")
+}
+
+func describeDetails(result *Result) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Deployed version: %s\n", result.DeployedVersion)
+	if result.DeployedAt != nil {
+		fmt.Fprintf(&b, "Deployed at: %s\n", result.DeployedAt.Format("2006-01-02 15:04:05"))
+	}
+	fmt.Fprintf(&b, "Infrastructure: %s\n", describeInfrastructure(result))
+	fmt.Fprintf(&b, "Undeployed versions: %s\n", describeUndeployed(result))
+	fmt.Fprintf(&b, "Local file: %s\n", orDash(result.LocalStatus))
+	return b.String()
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+func singleLine(s string) string {
+	s = strings.TrimSpace(s)
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		return s[:i] + " ..."
+	}
+	return s
+}
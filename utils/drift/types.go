@@ -0,0 +1,81 @@
+package drift
+
+import (
+	"time"
+
+	"tfvarenv/utils/plan"
+)
+
+const (
+	FormatTable = "table"
+	FormatJSON  = "json"
+	FormatJUnit = "junit"
+)
+
+// Environment statuses, from best to worst
+const (
+	StatusInSync      = "in_sync"
+	StatusNotDeployed = "not_deployed"
+	// StatusOutOfSync means the tfvars differ from what is deployed (undeployed versions or local changes)
+	StatusOutOfSync = "out_of_sync"
+	// StatusDrift means the infrastructure differs from the deployed configuration
+	StatusDrift = "drift"
+	StatusError = "error"
+)
+
+// Local file statuses compared with the deployed version
+const (
+	LocalInSync   = "in_sync"
+	LocalModified = "modified"
+	LocalMissing  = "missing"
+)
+
+// Exit codes of the drift command
+const (
+	ExitInSync    = 0
+	ExitError     = 1
+	ExitDrift     = 2
+	ExitOutOfSync = 3
+)
+
+// Result is the drift check of one environment
+type Result struct {
+	Environment string `json:"environment"`
+	Status      string `json:"status"`
+	// DeployedVersion is the version planned against, the last one applied
+	DeployedVersion string     `json:"deployed_version,omitempty"`
+	DeployedAt      *time.Time `json:"deployed_at,omitempty"`
+	Drift           bool       `json:"drift"`
+	// Changes are the resource counts of the drift plan
+	Changes *plan.Summary `json:"changes,omitempty"`
+	// UndeployedVersions are uploaded after the deployed version
+	LatestVersion      string        `json:"latest_version,omitempty"`
+	UndeployedVersions int           `json:"undeployed_versions"`
+	LocalStatus        string        `json:"local_status,omitempty"`
+	Error              string        `json:"error,omitempty"`
+	Duration           time.Duration `json:"duration"`
+}
+
+// Report is the drift check of several environments
+type Report struct {
+	CheckedAt time.Time `json:"checked_at"`
+	Results   []Result  `json:"results"`
+}
+
+// ExitCode returns the exit code for the worst result: errors, then drift, then out-of-sync tfvars
+func (r *Report) ExitCode() int {
+	code := ExitInSync
+	for _, result := range r.Results {
+		switch result.Status {
+		case StatusError:
+			return ExitError
+		case StatusDrift:
+			code = ExitDrift
+		case StatusOutOfSync:
+			if code == ExitInSync {
+				code = ExitOutOfSync
+			}
+		}
+	}
+	return code
+}
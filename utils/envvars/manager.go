@@ -0,0 +1,176 @@
+package envvars
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"tfvarenv/config"
+	"tfvarenv/utils/aws"
+)
+
+// Manager resolves the environment variables of an environment
+type Manager interface {
+	Variables(env *config.Environment) []Variable
+	Resolve(ctx context.Context, env *config.Environment) (map[string]string, error)
+}
+
+type manager struct {
+	awsClient aws.Client
+	clients   map[string]aws.Client
+}
+
+// NewManager creates a new environment variable manager. References are read
+// with awsClient, or with a client for the environment's region if it differs.
+func NewManager(awsClient aws.Client) Manager {
+	return &manager{
+		awsClient: awsClient,
+		clients:   make(map[string]aws.Client),
+	}
+}
+
+// Variables returns the configured variables sorted by name, without resolving references
+func (m *manager) Variables(env *config.Environment) []Variable {
+	vars := make([]Variable, 0, len(env.EnvVars))
+	for name, value := range env.EnvVars {
+		v := Variable{Name: name, Value: value}
+		for _, prefix := range []string{PrefixSSM, PrefixSecretsManager, PrefixEnv} {
+			if strings.HasPrefix(value, prefix) {
+				v.Source = strings.TrimSuffix(prefix, ":")
+				break
+			}
+		}
+		vars = append(vars, v)
+	}
+	sort.Slice(vars, func(i, j int) bool {
+		return vars[i].Name < vars[j].Name
+	})
+	return vars
+}
+
+// Resolve returns the variables of the environment with all references resolved
+func (m *manager) Resolve(ctx context.Context, env *config.Environment) (map[string]string, error) {
+	resolved := make(map[string]string, len(env.EnvVars))
+	for _, v := range m.Variables(env) {
+		value, err := m.resolve(ctx, env, v)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve environment variable %s: %w", v.Name, err)
+		}
+		resolved[v.Name] = value
+	}
+	return resolved, nil
+}
+
+func (m *manager) resolve(ctx context.Context, env *config.Environment, v Variable) (string, error) {
+	switch v.Source {
+	case "env":
+		name := strings.TrimPrefix(v.Value, PrefixEnv)
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			return "", fmt.Errorf("%s is not set", name)
+		}
+		return value, nil
+
+	case "ssm":
+		client, err := m.clientFor(env.AWS.Region)
+		if err != nil {
+			return "", err
+		}
+		return client.GetParameter(ctx, strings.TrimPrefix(v.Value, PrefixSSM))
+
+	case "secretsmanager":
+		client, err := m.clientFor(env.AWS.Region)
+		if err != nil {
+			return "", err
+		}
+		// secretsmanager:<id>#<key> は JSON シークレットの 1 キーを取り出す
+		secretID, key, hasKey := strings.Cut(strings.TrimPrefix(v.Value, PrefixSecretsManager), "#")
+		secret, err := client.GetSecretValue(ctx, secretID)
+		if err != nil {
+			return "", err
+		}
+		if !hasKey {
+			return secret, nil
+		}
+		return secretKey(secret, key)
+	}
+
+	return v.Value, nil
+}
+
+func (m *manager) clientFor(region string) (aws.Client, error) {
+	if region == "" {
+		return m.awsClient, nil
+	}
+	if client, ok := m.clients[region]; ok {
+		return client, nil
+	}
+	client, err := aws.NewClient(region)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create AWS client for region %s: %w", region, err)
+	}
+	m.clients[region] = client
+	return client, nil
+}
+
+func secretKey(secret, key string) (string, error) {
+	var values map[string]interface{}
+	if err := json.Unmarshal([]byte(secret), &values); err != nil {
+		return "", fmt.Errorf("secret is not a JSON object: %w", err)
+	}
+	value, ok := values[key]
+	if !ok {
+		return "", fmt.Errorf("secret has no key %q", key)
+	}
+	if s, ok := value.(string); ok {
+		return s, nil
+	}
+	b, err := json.Marshal(value)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode secret key %q: %w", key, err)
+	}
+	return string(b), nil
+}
+
+// Environ returns base with vars added, replacing variables that are already set
+func Environ(base []string, vars map[string]string) []string {
+	environ := make([]string, 0, len(base)+len(vars))
+	for _, kv := range base {
+		name, _, _ := strings.Cut(kv, "=")
+		if _, ok := vars[name]; ok {
+			continue
+		}
+		environ = append(environ, kv)
+	}
+	for _, name := range sortedNames(vars) {
+		environ = append(environ, name+"="+vars[name])
+	}
+	return environ
+}
+
+// WriteExports writes vars as POSIX shell export statements
+func WriteExports(w io.Writer, vars map[string]string) error {
+	for _, name := range sortedNames(vars) {
+		if _, err := fmt.Fprintf(w, "export %s=%s\n", name, shellQuote(vars[name])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+func sortedNames(vars map[string]string) []string {
+	names := make([]string, 0, len(vars))
+	for name := range vars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
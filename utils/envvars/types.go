@@ -0,0 +1,22 @@
+package envvars
+
+// Reference prefixes of environment variable values
+const (
+	PrefixSSM            = "ssm:"
+	PrefixSecretsManager = "secretsmanager:"
+	PrefixEnv            = "env:"
+)
+
+// Variable represents a configured environment variable
+type Variable struct {
+	Name string
+	// Value is the configured value, which may be a reference
+	Value string
+	// Source is the reference prefix without the colon, or empty for literal values
+	Source string
+}
+
+// IsSecret reports whether the value is read from SSM or Secrets Manager
+func (v Variable) IsSecret() bool {
+	return v.Source == "ssm" || v.Source == "secretsmanager"
+}
@@ -50,6 +50,12 @@ func (u *utils) CalculateHash(path string, opts *HashOptions) (string, error) {
 	return hex.EncodeToString(hash.Sum(nil)), nil
 }
 
+// HashContent returns the SHA-256 hash of the content in the same format as CalculateHash
+func HashContent(content []byte) string {
+	hash := sha256.Sum256(content)
+	return hex.EncodeToString(hash[:])
+}
+
 func (u *utils) CopyFile(src, dst string, opts *Options) error {
 	if opts == nil {
 		opts = &Options{
@@ -0,0 +1,115 @@
+package freeze
+
+import (
+	"fmt"
+	"time"
+
+	"tfvarenv/config"
+	"tfvarenv/utils/deployment"
+)
+
+// Active describes a freeze window that is in effect
+type Active struct {
+	Reason string
+	Start  time.Time
+	// End is zero when the end of a recurring window cannot be determined
+	End time.Time
+}
+
+func (a *Active) String() string {
+	if a.End.IsZero() {
+		return a.Reason
+	}
+	return fmt.Sprintf("%s (until %s)", a.Reason, a.End.Format("2006-01-02 15:04"))
+}
+
+// Enforce refuses to run inside a freeze window of the environment unless a break-glass reason is given.
+// It returns the break-glass to record, or nil if no window is in effect.
+func Enforce(env *config.Environment, breakGlass string, now time.Time) (*deployment.BreakGlass, error) {
+	active, err := Check(env.Deployment.FreezeWindows, now)
+	if err != nil {
+		return nil, err
+	}
+	if active == nil {
+		return nil, nil
+	}
+
+	if breakGlass == "" {
+		return nil, fmt.Errorf("environment %s is frozen: %s; use --break-glass \"reason\" to override",
+			env.Name, active)
+	}
+
+	fmt.Printf("\n!!! BREAK-GLASS: environment %s is frozen: %s\n", env.Name, active)
+	fmt.Printf("!!! Proceeding because: %s\n", breakGlass)
+	fmt.Printf("!!! This is recorded in the deployment history.\n")
+	return &deployment.BreakGlass{
+		Reason:       breakGlass,
+		FreezeWindow: active.String(),
+	}, nil
+}
+
+// Check returns the first window that contains now, or nil
+func Check(windows []config.FreezeWindow, now time.Time) (*Active, error) {
+	for _, window := range windows {
+		active, err := check(&window, now)
+		if err != nil {
+			return nil, fmt.Errorf("invalid freeze window %q: %w", window.Reason, err)
+		}
+		if active != nil {
+			return active, nil
+		}
+	}
+	return nil, nil
+}
+
+func check(window *config.FreezeWindow, now time.Time) (*Active, error) {
+	loc, err := window.GetLocation()
+	if err != nil {
+		return nil, err
+	}
+	now = now.In(loc)
+
+	if window.Schedule == "" {
+		start, end, err := window.GetRange(loc)
+		if err != nil {
+			return nil, err
+		}
+		if now.Before(start) || !now.Before(end) {
+			return nil, nil
+		}
+		return &Active{Reason: window.Reason, Start: start, End: end}, nil
+	}
+
+	schedule, err := window.GetSchedule()
+	if err != nil {
+		return nil, err
+	}
+	duration, err := window.GetDuration()
+	if err != nil {
+		return nil, err
+	}
+	minute := now.Truncate(time.Minute)
+
+	if duration == 0 {
+		if !schedule.Matches(minute) {
+			return nil, nil
+		}
+		// 連続して一致する範囲を窓とみなす
+		maxMinutes := int(config.MaxFreezeDuration / time.Minute)
+		start, end := minute, minute
+		for i := 0; i < maxMinutes && schedule.Matches(start.Add(-time.Minute)); i++ {
+			start = start.Add(-time.Minute)
+		}
+		for i := 0; i < maxMinutes && schedule.Matches(end.Add(time.Minute)); i++ {
+			end = end.Add(time.Minute)
+		}
+		return &Active{Reason: window.Reason, Start: start, End: end.Add(time.Minute)}, nil
+	}
+
+	for start := minute; now.Sub(start) < duration; start = start.Add(-time.Minute) {
+		if schedule.Matches(start) {
+			return &Active{Reason: window.Reason, Start: start, End: start.Add(duration)}, nil
+		}
+	}
+	return nil, nil
+}
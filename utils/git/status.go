@@ -0,0 +1,69 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// Status represents the state of a git working tree
+type Status struct {
+	Branch string
+	Commit string
+	// Dirty lists the paths with uncommitted changes
+	Dirty []string
+}
+
+// IsClean reports whether the working tree has no uncommitted changes
+func (s *Status) IsClean() bool {
+	return len(s.Dirty) == 0
+}
+
+// GetStatus returns the status of the git repository containing dir
+func GetStatus(ctx context.Context, dir string) (*Status, error) {
+	commit, err := run(ctx, dir, "rev-parse", "HEAD")
+	if err != nil {
+		return nil, err
+	}
+
+	branch, err := run(ctx, dir, "rev-parse", "--abbrev-ref", "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	if branch == "HEAD" {
+		// detached HEAD
+		branch = ""
+	}
+
+	porcelain, err := run(ctx, dir, "status", "--porcelain")
+	if err != nil {
+		return nil, err
+	}
+
+	status := &Status{
+		Branch: branch,
+		Commit: commit,
+		Dirty:  make([]string, 0),
+	}
+	for _, line := range strings.Split(porcelain, "\n") {
+		if len(line) > 3 {
+			status.Dirty = append(status.Dirty, line[3:])
+		}
+	}
+	return status, nil
+}
+
+func run(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("git %s failed: %s", strings.Join(args, " "), strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimRight(stdout.String(), "\n"), nil
+}
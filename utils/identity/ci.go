@@ -0,0 +1,74 @@
+package identity
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// DetectCI returns the CI run described by the environment, or nil outside CI
+func DetectCI() *CI {
+	switch {
+	case os.Getenv("GITHUB_ACTIONS") == "true":
+		ci := &CI{
+			Provider: "github-actions",
+			RunID:    os.Getenv("GITHUB_RUN_ID"),
+			Actor:    os.Getenv("GITHUB_ACTOR"),
+		}
+		if server, repo := os.Getenv("GITHUB_SERVER_URL"), os.Getenv("GITHUB_REPOSITORY"); server != "" && repo != "" && ci.RunID != "" {
+			ci.RunURL = fmt.Sprintf("%s/%s/actions/runs/%s", server, repo, ci.RunID)
+		}
+		return ci
+	case os.Getenv("GITLAB_CI") != "":
+		return &CI{
+			Provider: "gitlab",
+			RunID:    os.Getenv("CI_JOB_ID"),
+			RunURL:   os.Getenv("CI_JOB_URL"),
+			Actor:    os.Getenv("GITLAB_USER_LOGIN"),
+		}
+	case os.Getenv("CIRCLECI") == "true":
+		return &CI{
+			Provider: "circleci",
+			RunID:    os.Getenv("CIRCLE_BUILD_NUM"),
+			RunURL:   os.Getenv("CIRCLE_BUILD_URL"),
+			Actor:    os.Getenv("CIRCLE_USERNAME"),
+		}
+	case os.Getenv("BUILDKITE") == "true":
+		return &CI{
+			Provider: "buildkite",
+			RunID:    os.Getenv("BUILDKITE_BUILD_ID"),
+			RunURL:   os.Getenv("BUILDKITE_BUILD_URL"),
+			Actor:    os.Getenv("BUILDKITE_BUILD_CREATOR_EMAIL"),
+		}
+	case os.Getenv("TF_BUILD") == "True":
+		ci := &CI{
+			Provider: "azure-pipelines",
+			RunID:    os.Getenv("BUILD_BUILDID"),
+			Actor:    os.Getenv("BUILD_REQUESTEDFOREMAIL"),
+		}
+		if uri, project := os.Getenv("SYSTEM_COLLECTIONURI"), os.Getenv("SYSTEM_TEAMPROJECT"); uri != "" && ci.RunID != "" {
+			ci.RunURL = fmt.Sprintf("%s/%s/_build/results?buildId=%s", strings.TrimSuffix(uri, "/"), project, ci.RunID)
+		}
+		return ci
+	case os.Getenv("BITBUCKET_BUILD_NUMBER") != "":
+		ci := &CI{
+			Provider: "bitbucket",
+			RunID:    os.Getenv("BITBUCKET_BUILD_NUMBER"),
+			Actor:    os.Getenv("BITBUCKET_STEP_TRIGGERER_UUID"),
+		}
+		if repo := os.Getenv("BITBUCKET_REPO_FULL_NAME"); repo != "" {
+			ci.RunURL = fmt.Sprintf("https://bitbucket.org/%s/pipelines/results/%s", repo, ci.RunID)
+		}
+		return ci
+	case os.Getenv("JENKINS_URL") != "":
+		return &CI{
+			Provider: "jenkins",
+			RunID:    os.Getenv("BUILD_NUMBER"),
+			RunURL:   os.Getenv("BUILD_URL"),
+			Actor:    os.Getenv("BUILD_USER_ID"),
+		}
+	case os.Getenv("CI") != "":
+		return &CI{Provider: "unknown"}
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package identity
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/user"
+	"strings"
+
+	"tfvarenv/config"
+	"tfvarenv/utils/aws"
+	"tfvarenv/utils/git"
+)
+
+// Resolver identifies the user and context of a command
+type Resolver interface {
+	// Resolve collects the identity; workingDir is used for the git state
+	Resolve(ctx context.Context, workingDir string) (*Identity, error)
+}
+
+type resolver struct {
+	awsClient aws.Client
+	cfg       *config.IdentityConfig
+}
+
+// NewResolver creates a resolver using the project-wide identity settings
+func NewResolver(awsClient aws.Client, cfg *config.IdentityConfig) Resolver {
+	if cfg == nil {
+		cfg = &config.IdentityConfig{}
+	}
+	return &resolver{
+		awsClient: awsClient,
+		cfg:       cfg,
+	}
+}
+
+func (r *resolver) Resolve(ctx context.Context, workingDir string) (*Identity, error) {
+	id := &Identity{
+		User: localUser(),
+		CI:   DetectCI(),
+	}
+	id.Host, _ = os.Hostname()
+	if caller, err := r.awsClient.GetCallerIdentity(ctx); err == nil {
+		id.CallerArn = caller.Arn
+		id.AccountID = caller.Account
+	}
+	if status, err := git.GetStatus(ctx, workingDir); err == nil {
+		id.Git = &Git{
+			Commit: status.Commit,
+			Branch: status.Branch,
+			Dirty:  !status.IsClean(),
+		}
+	}
+
+	name := r.cfg.Resolver
+	if name == "" {
+		name = ResolverSTS
+	}
+	deployer, err := r.deployer(ctx, name, id)
+	if err != nil {
+		return nil, err
+	}
+	id.Deployer, id.Resolver = deployer, name
+
+	// 設定したリゾルバで特定できない場合は STS、ローカルユーザーの順に使う
+	if id.Deployer == "" && id.CallerArn != "" {
+		id.Deployer, id.Resolver = id.CallerArn, ResolverSTS
+	}
+	if id.Deployer == "" {
+		id.Deployer, id.Resolver = id.User, ResolverUser
+	}
+	return id, nil
+}
+
+// deployer returns the deployer name given by the resolver, or "" if it has none
+func (r *resolver) deployer(ctx context.Context, name string, id *Identity) (string, error) {
+	switch name {
+	case ResolverSTS:
+		return id.CallerArn, nil
+	case ResolverCI:
+		if id.CI == nil {
+			return "", nil
+		}
+		return id.CI.Actor, nil
+	case ResolverEnv:
+		envVar := r.cfg.EnvVar
+		if envVar == "" {
+			envVar = DefaultEnvVar
+		}
+		return os.Getenv(envVar), nil
+	case ResolverCommand:
+		return runCommand(ctx, r.cfg.Command)
+	case ResolverUser:
+		return id.User, nil
+	default:
+		return "", fmt.Errorf("unknown identity resolver: %s", name)
+	}
+}
+
+func runCommand(ctx context.Context, command []string) (string, error) {
+	if len(command) == 0 {
+		return "", fmt.Errorf("identity resolver 'command' requires a command")
+	}
+
+	cmd := exec.CommandContext(ctx, command[0], command[1:]...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("identity command failed: %s", strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// localUser returns the name of the local user; $USER is not set in many containers
+func localUser() string {
+	if name := os.Getenv("USER"); name != "" {
+		return name
+	}
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return ""
+}
@@ -0,0 +1,47 @@
+package identity
+
+// Resolvers that can be configured to name the deployer
+const (
+	// ResolverSTS uses the AWS caller ARN
+	ResolverSTS = "sts"
+	// ResolverCI uses the user that triggered the CI run
+	ResolverCI = "ci"
+	// ResolverEnv reads an environment variable
+	ResolverEnv = "env"
+	// ResolverCommand runs a command and uses its output
+	ResolverCommand = "command"
+	// ResolverUser uses the local user name
+	ResolverUser = "user"
+)
+
+// DefaultEnvVar is read by the env resolver when no variable is configured
+const DefaultEnvVar = "TFVARENV_DEPLOYER"
+
+// Identity describes who ran a command and from where
+type Identity struct {
+	// Deployer is the name recorded as DeployedBy
+	Deployer string `json:"deployer"`
+	// Resolver is the resolver that provided Deployer
+	Resolver  string `json:"resolver"`
+	CallerArn string `json:"caller_arn,omitempty"`
+	AccountID string `json:"account_id,omitempty"`
+	User      string `json:"user,omitempty"`
+	Host      string `json:"host,omitempty"`
+	Git       *Git   `json:"git,omitempty"`
+	CI        *CI    `json:"ci,omitempty"`
+}
+
+// Git is the state of the working directory
+type Git struct {
+	Commit string `json:"commit"`
+	Branch string `json:"branch,omitempty"`
+	Dirty  bool   `json:"dirty,omitempty"`
+}
+
+// CI describes the CI run the command was executed in
+type CI struct {
+	Provider string `json:"provider"`
+	RunID    string `json:"run_id,omitempty"`
+	RunURL   string `json:"run_url,omitempty"`
+	Actor    string `json:"actor,omitempty"`
+}
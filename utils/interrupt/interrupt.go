@@ -0,0 +1,72 @@
+package interrupt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// Error is the cancellation cause of a context cancelled by a signal
+type Error struct {
+	Signal os.Signal
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("interrupted by %s", e.Signal)
+}
+
+// NotifyContext returns a context that is cancelled with an *Error when SIGINT or
+// SIGTERM is received. A second signal exits immediately.
+func NotifyContext(parent context.Context) context.Context {
+	ctx, cancel := context.WithCancelCause(parent)
+
+	sigChan := make(chan os.Signal, 2)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		sig := <-sigChan
+		fmt.Fprintf(os.Stderr, "\nReceived %s. Waiting for terraform to stop gracefully (repeat to exit immediately)...\n", sig)
+		cancel(&Error{Signal: sig})
+
+		<-sigChan
+		fmt.Fprintln(os.Stderr, "\nExiting immediately")
+		os.Exit(130)
+	}()
+
+	return ctx
+}
+
+// Signal returns the signal that cancelled the context, or nil
+func Signal(ctx context.Context) os.Signal {
+	var interrupted *Error
+	if errors.As(context.Cause(ctx), &interrupted) {
+		return interrupted.Signal
+	}
+	return nil
+}
+
+// IsInterrupted reports whether the context was cancelled by a signal
+func IsInterrupted(ctx context.Context) bool {
+	return Signal(ctx) != nil
+}
+
+// Forward passes the signal that cancelled the context on to a child process.
+// SIGINT from a terminal already reaches the whole foreground process group, and
+// sending it again would make terraform abort without cleaning up.
+func Forward(ctx context.Context, process *os.Process) error {
+	sig := Signal(ctx)
+	if sig == nil {
+		return process.Kill()
+	}
+	if sig == os.Interrupt && isTerminal(os.Stdin) {
+		return nil
+	}
+	return process.Signal(sig)
+}
+
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
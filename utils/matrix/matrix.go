@@ -0,0 +1,134 @@
+package matrix
+
+import (
+	"sort"
+
+	"tfvarenv/utils/tfvars"
+)
+
+// Build builds the matrix from the tfvars file of each environment. Environments
+// missing from files are left out of the majority vote.
+func Build(envs []string, files map[string]*tfvars.File, opts *Options) *Matrix {
+	if opts == nil {
+		opts = &Options{}
+	}
+
+	m := &Matrix{
+		Environments: envs,
+		Variables:    opts.Variables,
+		Cells:        make(map[string]map[string]Cell),
+		Missing:      make(map[string]string),
+	}
+	if len(m.Variables) == 0 {
+		m.Variables = variableNames(envs, files)
+	}
+
+	for _, name := range m.Variables {
+		values := make(map[string]*tfvars.Variable)
+		for _, env := range envs {
+			if f, ok := files[env]; ok && f != nil {
+				v, _ := f.Get(name)
+				values[env] = v
+			}
+		}
+
+		masked := !opts.ShowSensitive && (opts.Sensitive[name] || IsSensitiveName(name))
+		differs := minorityEnvironments(values)
+
+		cells := make(map[string]Cell, len(values))
+		for env, v := range values {
+			cell := Cell{Differs: differs[env], Masked: masked && v != nil}
+			if v != nil {
+				cell.Set = true
+				cell.Value = v.Value
+			}
+			cells[env] = cell
+		}
+		m.Cells[name] = cells
+	}
+
+	return m
+}
+
+// variableNames returns every variable of all environments, sorted by name
+func variableNames(envs []string, files map[string]*tfvars.File) []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, env := range envs {
+		f := files[env]
+		if f == nil {
+			continue
+		}
+		for _, v := range f.Variables() {
+			if !seen[v.Name] {
+				seen[v.Name] = true
+				names = append(names, v.Name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// minorityEnvironments returns the environments whose value differs from the majority.
+// When there is no single majority value, every environment is reported.
+func minorityEnvironments(values map[string]*tfvars.Variable) map[string]bool {
+	type group struct {
+		value *tfvars.Variable
+		envs  []string
+	}
+
+	envs := make([]string, 0, len(values))
+	for env := range values {
+		envs = append(envs, env)
+	}
+	sort.Strings(envs)
+
+	var groups []*group
+	for _, env := range envs {
+		v := values[env]
+		var found *group
+		for _, g := range groups {
+			if g.value.Equal(v) {
+				found = g
+				break
+			}
+		}
+		if found == nil {
+			found = &group{value: v}
+			groups = append(groups, found)
+		}
+		found.envs = append(found.envs, env)
+	}
+
+	result := make(map[string]bool)
+	if len(groups) <= 1 {
+		return result
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		return len(groups[i].envs) > len(groups[j].envs)
+	})
+	start := 1
+	if len(groups[0].envs) == len(groups[1].envs) {
+		start = 0
+	}
+	for _, g := range groups[start:] {
+		for _, env := range g.envs {
+			result[env] = true
+		}
+	}
+	return result
+}
+
+// Display returns the value shown for the cell
+func (c Cell) Display() string {
+	switch {
+	case !c.Set:
+		return notSetValue
+	case c.Masked:
+		return maskedValue
+	default:
+		return c.Value
+	}
+}
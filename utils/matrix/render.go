@@ -0,0 +1,186 @@
+package matrix
+
+import (
+	"encoding/csv"
+	"fmt"
+	"html"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// Render writes the matrix in the given format
+func Render(w io.Writer, m *Matrix, format string) error {
+	switch format {
+	case FormatTable:
+		return renderTable(w, m)
+	case FormatMarkdown:
+		return renderMarkdown(w, m)
+	case FormatCSV:
+		return renderCSV(w, m)
+	case FormatHTML:
+		return renderHTML(w, m)
+	default:
+		return fmt.Errorf("unsupported format: %s (must be %s, %s, %s or %s)",
+			format, FormatTable, FormatMarkdown, FormatCSV, FormatHTML)
+	}
+}
+
+// cell returns the cell of a variable in an environment and whether the environment was loaded
+func (m *Matrix) cell(name, env string) (Cell, bool) {
+	c, ok := m.Cells[name][env]
+	return c, ok
+}
+
+func renderTable(w io.Writer, m *Matrix) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "VARIABLE\t%s\n", strings.Join(m.Environments, "\t"))
+
+	for _, name := range m.Variables {
+		row := []string{name}
+		for _, env := range m.Environments {
+			c, ok := m.cell(name, env)
+			switch {
+			case !ok:
+				row = append(row, "-")
+			case c.Differs:
+				row = append(row, "*"+singleLine(c.Display()))
+			default:
+				row = append(row, singleLine(c.Display()))
+			}
+		}
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "\n* differs from the majority of environments\n")
+	for _, env := range m.Environments {
+		if reason, ok := m.Missing[env]; ok {
+			fmt.Fprintf(w, "- %s: %s\n", env, reason)
+		}
+	}
+	return nil
+}
+
+func renderMarkdown(w io.Writer, m *Matrix) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "| Variable | %s |\n", strings.Join(m.Environments, " | "))
+	fmt.Fprintf(&b, "|----------|%s\n", strings.Repeat("------|", len(m.Environments)))
+
+	for _, name := range m.Variables {
+		fmt.Fprintf(&b, "| `%s` |", name)
+		for _, env := range m.Environments {
+			c, ok := m.cell(name, env)
+			value := "-"
+			if ok {
+				value = strings.ReplaceAll(singleLine(c.Display()), "|", "\\|")
+				if c.Set {
+					value = "`" + value + "`"
+				}
+				if c.Differs {
+					value = "**" + value + "**"
+				}
+			}
+			fmt.Fprintf(&b, " %s |", value)
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\nValues in **bold** differ from the majority of environments.\n")
+	for _, env := range m.Environments {
+		if reason, ok := m.Missing[env]; ok {
+			fmt.Fprintf(&b, "- %s: %s\n", env, reason)
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func renderCSV(w io.Writer, m *Matrix) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(append([]string{"variable"}, m.Environments...)); err != nil {
+		return err
+	}
+
+	for _, name := range m.Variables {
+		row := []string{name}
+		for _, env := range m.Environments {
+			c, ok := m.cell(name, env)
+			switch {
+			case !ok:
+				row = append(row, "")
+			case !c.Set:
+				row = append(row, "")
+			default:
+				row = append(row, c.Display())
+			}
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+func renderHTML(w io.Writer, m *Matrix) error {
+	var b strings.Builder
+	b.WriteString("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n<title>tfvarenv matrix</title>\n")
+	b.WriteString("<style>\n")
+	b.WriteString("table { border-collapse: collapse; font-family: sans-serif; }\n")
+	b.WriteString("th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: left; vertical-align: top; }\n")
+	b.WriteString("td pre { margin: 0; }\n")
+	b.WriteString(".differs { background: #fff3b0; }\n")
+	b.WriteString(".unset, .missing { color: #999; }\n")
+	b.WriteString("</style>\n</head>\n<body>\n<table>\n<tr><th>Variable</th>")
+	for _, env := range m.Environments {
+		fmt.Fprintf(&b, "<th>%s</th>", html.EscapeString(env))
+	}
+	b.WriteString("</tr>\n")
+
+	for _, name := range m.Variables {
+		fmt.Fprintf(&b, "<tr><th>%s</th>", html.EscapeString(name))
+		for _, env := range m.Environments {
+			c, ok := m.cell(name, env)
+			var classes []string
+			if !ok {
+				classes = append(classes, "missing")
+			} else if !c.Set {
+				classes = append(classes, "unset")
+			}
+			if c.Differs {
+				classes = append(classes, "differs")
+			}
+
+			value := "-"
+			if ok {
+				value = "<pre>" + html.EscapeString(c.Display()) + "</pre>"
+			}
+			if len(classes) > 0 {
+				fmt.Fprintf(&b, "<td class=\"%s\">%s</td>", strings.Join(classes, " "), value)
+			} else {
+				fmt.Fprintf(&b, "<td>%s</td>", value)
+			}
+		}
+		b.WriteString("</tr>\n")
+	}
+	b.WriteString("</table>\n")
+
+	for _, env := range m.Environments {
+		if reason, ok := m.Missing[env]; ok {
+			fmt.Fprintf(&b, "<p class=\"missing\">%s: %s</p>\n", html.EscapeString(env), html.EscapeString(reason))
+		}
+	}
+	b.WriteString("</body>\n</html>\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func singleLine(value string) string {
+	return strings.Join(strings.Fields(value), " ")
+}
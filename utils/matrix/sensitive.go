@@ -0,0 +1,102 @@
+package matrix
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+)
+
+// sensitiveNameParts are name fragments that mark a variable as sensitive
+var sensitiveNameParts = []string{
+	"password",
+	"passwd",
+	"secret",
+	"token",
+	"private_key",
+	"api_key",
+	"apikey",
+	"access_key",
+	"credential",
+}
+
+var variableFileSchema = &hcl.BodySchema{
+	Blocks: []hcl.BlockHeaderSchema{
+		{Type: "variable", LabelNames: []string{"name"}},
+	},
+}
+
+var variableBlockSchema = &hcl.BodySchema{
+	Attributes: []hcl.AttributeSchema{
+		{Name: "sensitive"},
+	},
+}
+
+// IsSensitiveName reports whether the variable name looks like it holds a secret
+func IsSensitiveName(name string) bool {
+	lower := strings.ToLower(name)
+	for _, part := range sensitiveNameParts {
+		if strings.Contains(lower, part) {
+			return true
+		}
+	}
+	return false
+}
+
+// LoadSensitiveVariables returns the variables declared with sensitive = true
+// in the terraform files of the given directory. Files that cannot be parsed are
+// skipped and reported in the error, together with the declarations that were read,
+// so that the caller can still mask them.
+func LoadSensitiveVariables(dir string) (map[string]bool, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.tf"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list terraform files: %w", err)
+	}
+
+	parser := hclparse.NewParser()
+	sensitive := make(map[string]bool)
+	var errs []error
+	for _, path := range paths {
+		file, diags := parser.ParseHCLFile(path)
+		if diags.HasErrors() {
+			errs = append(errs, fmt.Errorf("failed to parse %s: %s", path, diags.Error()))
+			continue
+		}
+
+		content, _, _ := file.Body.PartialContent(variableFileSchema)
+		for _, block := range content.Blocks {
+			attrs, _, _ := block.Body.PartialContent(variableBlockSchema)
+			attr, ok := attrs.Attributes["sensitive"]
+			if !ok {
+				continue
+			}
+			// 判定できない宣言はマスクする側に倒す
+			val, diags := attr.Expr.Value(nil)
+			if diags.HasErrors() || !val.IsKnown() {
+				sensitive[block.Labels[0]] = true
+				errs = append(errs, fmt.Errorf("%s: sensitive of variable %q must be a literal bool", path, block.Labels[0]))
+				continue
+			}
+			if val.IsNull() {
+				continue
+			}
+			// 文字列の "true" なども terraform と同様に bool に変換する
+			b, err := convert.Convert(val, cty.Bool)
+			if err != nil {
+				sensitive[block.Labels[0]] = true
+				errs = append(errs, fmt.Errorf("%s: sensitive of variable %q must be a bool: %v", path, block.Labels[0], err))
+				continue
+			}
+			if b.True() {
+				sensitive[block.Labels[0]] = true
+			}
+		}
+	}
+
+	return sensitive, errors.Join(errs...)
+}
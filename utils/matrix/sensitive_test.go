@@ -0,0 +1,61 @@
+package matrix
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"tfvarenv/utils/tfvars"
+)
+
+func writeTerraformFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadSensitiveVariablesSkipsMalformedFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeTerraformFile(t, dir, "variables.tf", `
+variable "admin_login" {
+  type      = string
+  sensitive = true
+}
+
+variable "instance_type" {
+  type = string
+}
+
+variable "db_host" {
+  sensitive = var.flag
+}
+`)
+	writeTerraformFile(t, dir, "broken.tf", `variable "oops" {`)
+
+	sensitive, err := LoadSensitiveVariables(dir)
+	if err == nil {
+		t.Fatal("expected an error for the malformed file")
+	}
+	if !sensitive["admin_login"] {
+		t.Errorf("admin_login should be sensitive, got %v", sensitive)
+	}
+	if !sensitive["db_host"] {
+		t.Errorf("db_host with a non-literal sensitive should be masked, got %v", sensitive)
+	}
+	if sensitive["instance_type"] {
+		t.Errorf("instance_type should not be sensitive")
+	}
+
+	file, err := tfvars.Parse([]byte("admin_login = \"root\"\ninstance_type = \"t3.micro\"\n"), "terraform.tfvars")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := Build([]string{"dev"}, map[string]*tfvars.File{"dev": file}, &Options{Sensitive: sensitive})
+	if cell := m.Cells["admin_login"]["dev"]; !cell.Masked {
+		t.Errorf("admin_login should be masked, got %+v", cell)
+	}
+	if cell := m.Cells["instance_type"]["dev"]; cell.Masked {
+		t.Errorf("instance_type should not be masked, got %+v", cell)
+	}
+}
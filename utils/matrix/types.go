@@ -0,0 +1,42 @@
+package matrix
+
+const (
+	FormatTable    = "table"
+	FormatMarkdown = "markdown"
+	FormatCSV      = "csv"
+	FormatHTML     = "html"
+)
+
+const (
+	maskedValue = "********"
+	notSetValue = "(not set)"
+)
+
+// Matrix represents variable values across environments
+type Matrix struct {
+	Environments []string
+	Variables    []string
+	// Cells maps a variable name to its cell in each environment
+	Cells map[string]map[string]Cell
+	// Missing lists environments whose tfvars could not be loaded
+	Missing map[string]string
+}
+
+// Cell represents the value of a variable in an environment
+type Cell struct {
+	Value  string
+	Set    bool
+	Masked bool
+	// Differs is set when the value differs from the majority of environments
+	Differs bool
+}
+
+// Options represents options for building a matrix
+type Options struct {
+	// Variables limits the matrix to the given variables, in that order
+	Variables []string
+	// Sensitive lists variables whose values are masked
+	Sensitive map[string]bool
+	// ShowSensitive disables masking
+	ShowSensitive bool
+}
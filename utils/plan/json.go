@@ -0,0 +1,134 @@
+package plan
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Resource change actions
+const (
+	ActionCreate  = "create"
+	ActionUpdate  = "update"
+	ActionDelete  = "delete"
+	ActionReplace = "replace"
+	ActionRead    = "read"
+	ActionNoop    = "no-op"
+)
+
+// Change is a resource change of a saved plan
+type Change struct {
+	Address       string
+	ModuleAddress string
+	Type          string
+	Action        string
+	Importing     bool
+}
+
+// RelativeAddress returns the address without the module path
+func (c Change) RelativeAddress() string {
+	if c.ModuleAddress == "" {
+		return c.Address
+	}
+	return strings.TrimPrefix(c.Address, c.ModuleAddress+".")
+}
+
+// IsDestructive reports whether the resource would be deleted or replaced
+func (c Change) IsDestructive() bool {
+	return c.Action == ActionDelete || c.Action == ActionReplace
+}
+
+// Plan represents the resource changes of a saved plan
+type Plan struct {
+	Changes []Change
+}
+
+// jsonPlan is the subset of 'terraform show -json' used by tfvarenv
+type jsonPlan struct {
+	ResourceChanges []struct {
+		Address       string `json:"address"`
+		ModuleAddress string `json:"module_address"`
+		Type          string `json:"type"`
+		Change        struct {
+			Actions   []string        `json:"actions"`
+			Importing json.RawMessage `json:"importing"`
+		} `json:"change"`
+	} `json:"resource_changes"`
+}
+
+// ParseJSON parses the output of 'terraform show -json <planfile>'
+func ParseJSON(data []byte) (*Plan, error) {
+	var raw jsonPlan
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to decode plan: %w", err)
+	}
+
+	p := &Plan{Changes: make([]Change, 0, len(raw.ResourceChanges))}
+	for _, rc := range raw.ResourceChanges {
+		p.Changes = append(p.Changes, Change{
+			Address:       rc.Address,
+			ModuleAddress: rc.ModuleAddress,
+			Type:          rc.Type,
+			Action:        action(rc.Change.Actions),
+			Importing:     len(rc.Change.Importing) > 0 && string(rc.Change.Importing) != "null",
+		})
+	}
+	return p, nil
+}
+
+func action(actions []string) string {
+	if len(actions) == 2 {
+		// ["delete", "create"] or ["create", "delete"]
+		return ActionReplace
+	}
+	if len(actions) == 1 {
+		return actions[0]
+	}
+	return ActionNoop
+}
+
+// Summary returns the resource counts of the plan, counting replacements as add and destroy like terraform
+func (p *Plan) Summary() *Summary {
+	s := &Summary{}
+	for _, c := range p.Changes {
+		if c.Importing {
+			s.Import++
+		}
+		switch c.Action {
+		case ActionCreate:
+			s.Add++
+		case ActionUpdate:
+			s.Change++
+		case ActionDelete:
+			s.Destroy++
+		case ActionReplace:
+			s.Add++
+			s.Destroy++
+		}
+	}
+	s.Changed = s.Import+s.Add+s.Change+s.Destroy > 0
+	return s
+}
+
+// Changed returns the changes that create, update, delete or replace a resource
+func (p *Plan) Changed() []Change {
+	changed := make([]Change, 0)
+	for _, c := range p.Changes {
+		switch c.Action {
+		case ActionCreate, ActionUpdate, ActionDelete, ActionReplace:
+			changed = append(changed, c)
+		}
+	}
+	return changed
+}
+
+// Destructive returns the changes that delete or replace a resource
+func (p *Plan) Destructive() []Change {
+	destructive := make([]Change, 0)
+	for _, c := range p.Changes {
+		if c.IsDestructive() {
+			destructive = append(destructive, c)
+		}
+	}
+	return destructive
+}
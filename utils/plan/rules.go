@@ -0,0 +1,82 @@
+package plan
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"tfvarenv/config"
+)
+
+// CheckResult is the outcome of the plan safety checks, attached to the deployment record
+type CheckResult struct {
+	Summary  *Summary `json:"summary"`
+	Deleted  []string `json:"deleted,omitempty"`
+	Replaced []string `json:"replaced,omitempty"`
+	// Forced is set when --force overrode a rule
+	Forced     bool     `json:"forced,omitempty"`
+	Violations []string `json:"violations,omitempty"`
+}
+
+// Violation is a plan rule the plan does not satisfy
+type Violation struct {
+	Rule    string
+	Message string
+	// Overridable violations can be overridden with --force
+	Overridable bool
+}
+
+// NewCheckResult summarizes the plan for the deployment record
+func NewCheckResult(p *Plan) *CheckResult {
+	result := &CheckResult{Summary: p.Summary()}
+	for _, c := range p.Destructive() {
+		if c.Action == ActionReplace {
+			result.Replaced = append(result.Replaced, c.Address)
+		} else {
+			result.Deleted = append(result.Deleted, c.Address)
+		}
+	}
+	return result
+}
+
+// CheckRules checks the plan against the plan rules of the environment
+func CheckRules(p *Plan, rules *config.PlanRules) []Violation {
+	violations := make([]Violation, 0)
+	if rules == nil {
+		return violations
+	}
+
+	for _, c := range p.Destructive() {
+		for _, pattern := range rules.DenyDestroy {
+			if MatchAddress(pattern, c) {
+				violations = append(violations, Violation{
+					Rule:    "deny_destroy",
+					Message: fmt.Sprintf("%s would be %sd (denied by %s)", c.Address, c.Action, pattern),
+				})
+				break
+			}
+		}
+	}
+
+	if rules.MaxChanges > 0 {
+		if n := len(p.Changed()); n > rules.MaxChanges {
+			violations = append(violations, Violation{
+				Rule:        "max_changes",
+				Message:     fmt.Sprintf("%d resources would change (maximum %d without --force)", n, rules.MaxChanges),
+				Overridable: true,
+			})
+		}
+	}
+	return violations
+}
+
+// MatchAddress matches a pattern ('*' matches any characters) against the full
+// address of the change or its address within the module
+func MatchAddress(pattern string, c Change) bool {
+	expr := "^" + strings.ReplaceAll(regexp.QuoteMeta(pattern), `\*`, ".*") + "$"
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return false
+	}
+	return re.MatchString(c.Address) || re.MatchString(c.RelativeAddress())
+}
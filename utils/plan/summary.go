@@ -0,0 +1,47 @@
+package plan
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// Summary represents the resource counts of a terraform plan
+type Summary struct {
+	Import  int  `json:"import,omitempty"`
+	Add     int  `json:"add"`
+	Change  int  `json:"change"`
+	Destroy int  `json:"destroy"`
+	Changed bool `json:"changed"`
+}
+
+var (
+	summaryPattern   = regexp.MustCompile(`Plan: (?:(\d+) to import, )?(\d+) to add, (\d+) to change, (\d+) to destroy`)
+	noChangesPattern = regexp.MustCompile(`No changes\.`)
+)
+
+// ParseSummary extracts the summary line from the human-readable plan output
+func ParseSummary(output string) (*Summary, error) {
+	if m := summaryPattern.FindStringSubmatch(output); m != nil {
+		s := &Summary{Changed: true}
+		s.Import, _ = strconv.Atoi(m[1])
+		s.Add, _ = strconv.Atoi(m[2])
+		s.Change, _ = strconv.Atoi(m[3])
+		s.Destroy, _ = strconv.Atoi(m[4])
+		return s, nil
+	}
+	if noChangesPattern.MatchString(output) {
+		return &Summary{}, nil
+	}
+	return nil, fmt.Errorf("plan summary not found in terraform output")
+}
+
+func (s *Summary) String() string {
+	if !s.Changed {
+		return "No changes"
+	}
+	if s.Import > 0 {
+		return fmt.Sprintf("%d to import, %d to add, %d to change, %d to destroy", s.Import, s.Add, s.Change, s.Destroy)
+	}
+	return fmt.Sprintf("%d to add, %d to change, %d to destroy", s.Add, s.Change, s.Destroy)
+}
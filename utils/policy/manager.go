@@ -0,0 +1,268 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"tfvarenv/config"
+	"tfvarenv/utils/aws"
+	"tfvarenv/utils/deployment"
+	"tfvarenv/utils/file"
+	"tfvarenv/utils/git"
+	"tfvarenv/utils/version"
+)
+
+// Manager evaluates the deployment policy of an environment
+type Manager interface {
+	Evaluate(ctx context.Context, input *Input) (*Report, error)
+	Enforce(ctx context.Context, input *Input, explain bool) error
+}
+
+// EnvironmentLookup returns the configuration of another environment by name
+type EnvironmentLookup func(name string) (*config.Environment, error)
+
+type manager struct {
+	awsClient   aws.Client
+	fileUtils   file.Utils
+	environment EnvironmentLookup
+}
+
+// NewManager creates a new policy manager
+func NewManager(awsClient aws.Client, fileUtils file.Utils, environment EnvironmentLookup) Manager {
+	return &manager{
+		awsClient:   awsClient,
+		fileUtils:   fileUtils,
+		environment: environment,
+	}
+}
+
+// Evaluate checks every configured rule. Rules that do not apply to the command are skipped.
+func (m *manager) Evaluate(ctx context.Context, input *Input) (*Report, error) {
+	report := &Report{
+		Environment: input.Environment.Name,
+		Command:     input.Command,
+		Results:     make([]Result, 0),
+	}
+
+	policy := input.Environment.Deployment.Policy
+	if policy == nil {
+		return report, nil
+	}
+
+	if len(policy.AllowedDeployers) > 0 {
+		result, err := m.checkDeployer(ctx, policy.AllowedDeployers)
+		if err != nil {
+			return nil, err
+		}
+		report.Results = append(report.Results, result)
+	}
+
+	if len(policy.AllowedBranches) > 0 || policy.RequireCleanGitTree {
+		status, gitErr := git.GetStatus(ctx, input.Environment.GetWorkingDir())
+		if len(policy.AllowedBranches) > 0 {
+			report.Results = append(report.Results, checkBranch(status, gitErr, policy.AllowedBranches))
+		}
+		if policy.RequireCleanGitTree {
+			report.Results = append(report.Results, checkCleanTree(status, gitErr))
+		}
+	}
+
+	applyOnly := input.Command != deployment.CommandApply
+	if policy.RequireDescription {
+		result := Result{Rule: RuleDescription, Skipped: applyOnly}
+		switch {
+		case applyOnly:
+			result.Message = "only checked for apply"
+		case input.Version == nil || strings.TrimSpace(input.Version.Description) == "":
+			result.Message = "the version has no description"
+		default:
+			result.Passed = true
+			result.Message = fmt.Sprintf("description: %q", input.Version.Description)
+		}
+		report.Results = append(report.Results, result)
+	}
+
+	if policy.TicketPattern != "" {
+		result := Result{Rule: RuleTicketPattern, Skipped: applyOnly}
+		pattern, err := regexp.Compile(policy.TicketPattern)
+		switch {
+		case applyOnly:
+			result.Message = "only checked for apply"
+		case err != nil:
+			result.Message = fmt.Sprintf("invalid pattern: %v", err)
+		case input.Version == nil || !pattern.MatchString(input.Version.Description):
+			result.Message = fmt.Sprintf("the version description does not reference a ticket (%s)", policy.TicketPattern)
+		default:
+			result.Passed = true
+			result.Message = fmt.Sprintf("ticket %s", pattern.FindString(input.Version.Description))
+		}
+		report.Results = append(report.Results, result)
+	}
+
+	if req := policy.RequiredDeployments; req != nil {
+		if applyOnly {
+			report.Results = append(report.Results, Result{
+				Rule:    RuleRequiredDeployments,
+				Skipped: true,
+				Message: "only checked for apply",
+			})
+		} else {
+			result, err := m.checkRequiredDeployments(ctx, req, input.Version)
+			if err != nil {
+				return nil, err
+			}
+			report.Results = append(report.Results, result)
+		}
+	}
+
+	if policy.ForbidAutoApprove {
+		result := Result{Rule: RuleForbidAutoApprove, Passed: !input.AutoApprove}
+		if input.AutoApprove {
+			result.Message = "--auto-approve is not allowed in this environment"
+		} else {
+			result.Message = "interactive approval"
+		}
+		report.Results = append(report.Results, result)
+	}
+
+	return report, nil
+}
+
+// Enforce evaluates the policy, writes the report and returns an error if the run is blocked
+func (m *manager) Enforce(ctx context.Context, input *Input, explain bool) error {
+	report, err := m.Evaluate(ctx, input)
+	if err != nil {
+		return err
+	}
+
+	WriteReport(os.Stdout, report, explain)
+	return Error(report)
+}
+
+func (m *manager) checkDeployer(ctx context.Context, patterns []string) (Result, error) {
+	identity, err := m.awsClient.GetCallerIdentity(ctx)
+	if err != nil {
+		return Result{}, err
+	}
+
+	result := Result{Rule: RuleAllowedDeployers}
+	for _, pattern := range patterns {
+		if matchPattern(pattern, identity.Arn) {
+			result.Passed = true
+			result.Message = fmt.Sprintf("%s matches %s", identity.Arn, pattern)
+			return result, nil
+		}
+	}
+	result.Message = fmt.Sprintf("%s is not an allowed deployer (allowed: %s)", identity.Arn, strings.Join(patterns, ", "))
+	return result, nil
+}
+
+func checkBranch(status *git.Status, gitErr error, patterns []string) Result {
+	result := Result{Rule: RuleAllowedBranches}
+	switch {
+	case gitErr != nil:
+		result.Message = fmt.Sprintf("cannot determine the git branch: %v", gitErr)
+		return result
+	case status.Branch == "":
+		result.Message = fmt.Sprintf("HEAD is detached at %s", shortCommit(status.Commit))
+		return result
+	}
+
+	for _, pattern := range patterns {
+		if matchPattern(pattern, status.Branch) {
+			result.Passed = true
+			result.Message = fmt.Sprintf("branch %s matches %s", status.Branch, pattern)
+			return result
+		}
+	}
+	result.Message = fmt.Sprintf("branch %s is not allowed (allowed: %s)", status.Branch, strings.Join(patterns, ", "))
+	return result
+}
+
+func checkCleanTree(status *git.Status, gitErr error) Result {
+	result := Result{Rule: RuleCleanGitTree}
+	switch {
+	case gitErr != nil:
+		result.Message = fmt.Sprintf("cannot determine the git status: %v", gitErr)
+	case !status.IsClean():
+		files := status.Dirty
+		if len(files) > 5 {
+			files = append(files[:5:5], fmt.Sprintf("and %d more", len(status.Dirty)-5))
+		}
+		result.Message = fmt.Sprintf("uncommitted changes: %s", strings.Join(files, ", "))
+	default:
+		result.Passed = true
+		result.Message = fmt.Sprintf("clean at %s", shortCommit(status.Commit))
+	}
+	return result
+}
+
+// checkRequiredDeployments counts successful applies of the same content hash in the other environment
+func (m *manager) checkRequiredDeployments(ctx context.Context, req *config.RequiredDeployments, ver *version.Version) (Result, error) {
+	result := Result{Rule: RuleRequiredDeployments}
+	if ver == nil || ver.Hash == "" {
+		result.Message = "the content hash of the version is unknown"
+		return result, nil
+	}
+
+	other, err := m.environment(req.Environment)
+	if err != nil {
+		return result, fmt.Errorf("failed to get environment %s: %w", req.Environment, err)
+	}
+
+	versions, err := version.NewManager(m.awsClient, m.fileUtils, other).GetVersions(ctx, nil)
+	if err != nil {
+		return result, fmt.Errorf("failed to get versions of %s: %w", other.Name, err)
+	}
+	sameContent := make(map[string]bool)
+	var since time.Time
+	for _, v := range versions {
+		if v.Hash != ver.Hash {
+			continue
+		}
+		sameContent[v.VersionID] = true
+		if since.IsZero() || v.Timestamp.Before(since) {
+			since = v.Timestamp
+		}
+	}
+
+	count := 0
+	if len(sameContent) > 0 {
+		// 同じ内容が最初にアップロードされる前の記録は読まない
+		records, err := deployment.NewManager(m.awsClient, other).QueryDeployments(ctx, deployment.QueryOptions{
+			Since:   since,
+			Command: deployment.CommandApply,
+			Status:  deployment.StatusSuccess,
+		})
+		if err != nil {
+			return result, fmt.Errorf("failed to get deployment history of %s: %w", other.Name, err)
+		}
+		for _, record := range records {
+			if sameContent[record.VersionID] {
+				count++
+			}
+		}
+	}
+
+	result.Passed = count >= req.Count
+	result.Message = fmt.Sprintf("%d of %d successful deployment(s) of the same content in %s", count, req.Count, other.Name)
+	return result, nil
+}
+
+// matchPattern matches value against a pattern where '*' matches any characters
+func matchPattern(pattern, value string) bool {
+	expr := "^" + strings.ReplaceAll(regexp.QuoteMeta(pattern), `\*`, ".*") + "$"
+	matched, _ := regexp.MatchString(expr, value)
+	return matched
+}
+
+func shortCommit(commit string) string {
+	if len(commit) > 8 {
+		return commit[:8]
+	}
+	return commit
+}
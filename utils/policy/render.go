@@ -0,0 +1,53 @@
+package policy
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WriteReport writes the violations of the report, or every rule with explain
+func WriteReport(w io.Writer, report *Report, explain bool) {
+	results := report.Violations()
+	if explain {
+		results = report.Results
+	}
+	if len(results) == 0 {
+		if explain {
+			fmt.Fprintf(w, "\nNo deployment policy configured for environment '%s'\n", report.Environment)
+		}
+		return
+	}
+
+	fmt.Fprintf(w, "\nDeployment policy for environment '%s' (%s):\n", report.Environment, report.Command)
+	for _, result := range results {
+		fmt.Fprintf(w, "  %-4s  %-24s %s\n", label(result), result.Rule, result.Message)
+	}
+	if !explain {
+		fmt.Fprintf(w, "Run with --explain to see all rules\n")
+	}
+}
+
+// Error returns an error naming the violated rules, or nil if the run is allowed
+func Error(report *Report) error {
+	violations := report.Violations()
+	if len(violations) == 0 {
+		return nil
+	}
+	rules := make([]string, 0, len(violations))
+	for _, v := range violations {
+		rules = append(rules, v.Rule)
+	}
+	return fmt.Errorf("%s blocked by deployment policy: %s", report.Command, strings.Join(rules, ", "))
+}
+
+func label(result Result) string {
+	switch {
+	case result.Skipped:
+		return "SKIP"
+	case result.Passed:
+		return "PASS"
+	default:
+		return "FAIL"
+	}
+}
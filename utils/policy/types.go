@@ -0,0 +1,57 @@
+package policy
+
+import (
+	"tfvarenv/config"
+	"tfvarenv/utils/version"
+)
+
+// Rule names as they appear in the policy configuration
+const (
+	RuleAllowedDeployers    = "allowed_deployers"
+	RuleAllowedBranches     = "allowed_branches"
+	RuleCleanGitTree        = "require_clean_git_tree"
+	RuleDescription         = "require_description"
+	RuleTicketPattern       = "ticket_pattern"
+	RuleRequiredDeployments = "required_deployments"
+	RuleForbidAutoApprove   = "forbid_auto_approve"
+)
+
+// Input describes the run being checked
+type Input struct {
+	Environment *config.Environment
+	Command     string
+	// Version is the version being applied, or the last deployed version for destroy
+	Version     *version.Version
+	AutoApprove bool
+}
+
+// Result is the outcome of a single rule
+type Result struct {
+	Rule    string
+	Passed  bool
+	Skipped bool
+	Message string
+}
+
+// Report is the outcome of all configured rules
+type Report struct {
+	Environment string
+	Command     string
+	Results     []Result
+}
+
+// Allowed reports whether no rule was violated
+func (r *Report) Allowed() bool {
+	return len(r.Violations()) == 0
+}
+
+// Violations returns the rules that blocked the run
+func (r *Report) Violations() []Result {
+	violations := make([]Result, 0)
+	for _, result := range r.Results {
+		if !result.Passed && !result.Skipped {
+			violations = append(violations, result)
+		}
+	}
+	return violations
+}
@@ -24,3 +24,48 @@ func PromptYesNo(prompt string, defaultValue bool) bool {
 	}
 	return input == "y" || input == "yes"
 }
+
+// PromptChoice prompts the user to pick one of the given choices.
+// It keeps asking until a valid choice is entered.
+func PromptChoice(prompt string, choices []string, defaultValue string) string {
+	reader := bufio.NewReader(os.Stdin)
+	for {
+		fmt.Printf("%s [%s]: ", prompt, strings.Join(choices, "/"))
+		input, err := reader.ReadString('\n')
+		input = strings.ToLower(strings.TrimSpace(input))
+
+		if input == "" {
+			if defaultValue != "" || err != nil {
+				return defaultValue
+			}
+			continue
+		}
+		for _, choice := range choices {
+			if input == strings.ToLower(choice) {
+				return choice
+			}
+		}
+		if err != nil {
+			return defaultValue
+		}
+		fmt.Printf("Please enter one of: %s\n", strings.Join(choices, ", "))
+	}
+}
+
+// PromptString prompts the user for a line of text.
+func PromptString(prompt string, defaultValue string) string {
+	if defaultValue != "" {
+		fmt.Printf("%s [%s]: ", prompt, defaultValue)
+	} else {
+		fmt.Printf("%s: ", prompt)
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	input, _ := reader.ReadString('\n')
+	input = strings.TrimSpace(input)
+
+	if input == "" {
+		return defaultValue
+	}
+	return input
+}
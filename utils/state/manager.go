@@ -0,0 +1,143 @@
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"tfvarenv/utils/file"
+)
+
+const (
+	StateFormatVersion = "1.0"
+	stateFileName      = ".tfvarenv.state.json"
+)
+
+// Manager provides access to the local state file
+type Manager interface {
+	GetEnvironmentState(envName string) (*EnvironmentState, error)
+	SetParentVersion(envName, versionID, hash string) error
+	SetMergeState(envName string, merge *MergeState) error
+	GetCurrentEnvironment() (string, error)
+	SetCurrentEnvironment(envName string) error
+}
+
+type manager struct {
+	fileUtils file.Utils
+	path      string
+}
+
+// NewManager creates a new local state manager
+func NewManager(fileUtils file.Utils) Manager {
+	return &manager{
+		fileUtils: fileUtils,
+		path:      stateFileName,
+	}
+}
+
+func (m *manager) GetEnvironmentState(envName string) (*EnvironmentState, error) {
+	st, err := m.load()
+	if err != nil {
+		return nil, err
+	}
+
+	envState, exists := st.Environments[envName]
+	if !exists {
+		return &EnvironmentState{}, nil
+	}
+	return &envState, nil
+}
+
+// SetParentVersion records the parent version of the local file and clears any pending merge
+func (m *manager) SetParentVersion(envName, versionID, hash string) error {
+	st, err := m.load()
+	if err != nil {
+		return err
+	}
+
+	envState := st.Environments[envName]
+	envState.ParentVersionID = versionID
+	envState.ParentHash = hash
+	envState.Merge = nil
+	envState.UpdatedAt = time.Now()
+	st.Environments[envName] = envState
+
+	return m.save(st)
+}
+
+// SetMergeState records a pending merge. A nil merge clears it.
+func (m *manager) SetMergeState(envName string, merge *MergeState) error {
+	st, err := m.load()
+	if err != nil {
+		return err
+	}
+
+	envState := st.Environments[envName]
+	envState.Merge = merge
+	envState.UpdatedAt = time.Now()
+	st.Environments[envName] = envState
+
+	return m.save(st)
+}
+
+func (m *manager) GetCurrentEnvironment() (string, error) {
+	st, err := m.load()
+	if err != nil {
+		return "", err
+	}
+	return st.CurrentEnvironment, nil
+}
+
+func (m *manager) SetCurrentEnvironment(envName string) error {
+	st, err := m.load()
+	if err != nil {
+		return err
+	}
+
+	st.CurrentEnvironment = envName
+	return m.save(st)
+}
+
+func (m *manager) load() (*State, error) {
+	st := &State{
+		FormatVersion: StateFormatVersion,
+		Environments:  make(map[string]EnvironmentState),
+	}
+
+	exists, err := m.fileUtils.FileExists(m.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check state file: %w", err)
+	}
+	if !exists {
+		return st, nil
+	}
+
+	content, err := m.fileUtils.ReadFile(m.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state file: %w", err)
+	}
+	if err := json.Unmarshal(content, st); err != nil {
+		return nil, fmt.Errorf("failed to decode state file: %w", err)
+	}
+	if st.Environments == nil {
+		st.Environments = make(map[string]EnvironmentState)
+	}
+
+	return st, nil
+}
+
+func (m *manager) save(st *State) error {
+	data, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal state: %w", err)
+	}
+
+	opts := &file.Options{
+		CreateDirs: true,
+		Overwrite:  true,
+	}
+	if err := m.fileUtils.WriteFile(m.path, append(data, '\n'), opts); err != nil {
+		return fmt.Errorf("failed to save state file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+package state
+
+import (
+	"time"
+)
+
+// State represents the local state file structure
+type State struct {
+	FormatVersion string `json:"format_version"`
+	// CurrentEnvironment is the environment selected with 'tfvarenv use'
+	CurrentEnvironment string                      `json:"current_environment,omitempty"`
+	Environments       map[string]EnvironmentState `json:"environments"`
+}
+
+// EnvironmentState represents the local state of a single environment
+type EnvironmentState struct {
+	// ParentVersionID is the remote version the local tfvars file was derived from
+	ParentVersionID string      `json:"parent_version_id,omitempty"`
+	ParentHash      string      `json:"parent_hash,omitempty"`
+	Merge           *MergeState `json:"merge,omitempty"`
+	UpdatedAt       time.Time   `json:"updated_at"`
+}
+
+// MergeState represents a merge waiting for conflicts to be resolved in the local file
+type MergeState struct {
+	BaseVersionID   string    `json:"base_version_id,omitempty"`
+	RemoteVersionID string    `json:"remote_version_id"`
+	BackupPath      string    `json:"backup_path,omitempty"`
+	StartedAt       time.Time `json:"started_at"`
+}
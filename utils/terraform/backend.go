@@ -1,10 +1,15 @@
 package terraform
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"text/template"
+
+	"tfvarenv/config"
 )
 
 // BackendTemplateData represents the data needed for backend configuration
@@ -63,3 +68,139 @@ func ValidateBackendConfig(path string) error {
 	// TODO: Add more validation logic if needed
 	return nil
 }
+
+// GetBackendConfig returns the backend configuration the environment's data directory is initialized with
+func (r *runner) GetBackendConfig(env *config.Environment) (*config.BackendConfig, error) {
+	statePath := filepath.Join(r.DataDir(env), "terraform.tfstate")
+	exists, err := r.fileUtils.FileExists(statePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check terraform state file: %w", err)
+	}
+	if !exists {
+		return nil, fmt.Errorf("terraform state file not found at %s", statePath)
+	}
+
+	content, err := r.fileUtils.ReadFile(statePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read terraform state file: %w", err)
+	}
+
+	var stateData map[string]interface{}
+	if err := json.Unmarshal(content, &stateData); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal terraform state file: %w", err)
+	}
+
+	backend, ok := stateData["backend"].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("backend information not found in terraform state file")
+	}
+
+	backendConfig, ok := backend["config"].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("backend config not found in terraform state file")
+	}
+
+	bucket, ok := backendConfig["bucket"].(string)
+	if !ok {
+		return nil, fmt.Errorf("bucket not found in terraform state file")
+	}
+
+	key, ok := backendConfig["key"].(string)
+	if !ok {
+		return nil, fmt.Errorf("key not found in terraform state file")
+	}
+
+	region, ok := backendConfig["region"].(string)
+	if !ok {
+		return nil, fmt.Errorf("region not found in terraform state file")
+	}
+
+	return &config.BackendConfig{
+		Bucket: bucket,
+		Key:    key,
+		Region: region,
+	}, nil
+}
+
+// EnsureBackend checks that the environment's data directory is initialized with the
+// backend of the environment. A data directory that was never initialized is initialized
+// automatically; a mismatched one is only re-initialized with reinit.
+func (r *runner) EnsureBackend(ctx context.Context, env *config.Environment, reinit bool) error {
+	return r.ensureBackend(ctx, env, reinit, false)
+}
+
+// ensureBackend is EnsureBackend without progress and init output when quiet is set
+func (r *runner) ensureBackend(ctx context.Context, env *config.Environment, reinit, quiet bool) error {
+	var progress io.Writer = os.Stdout
+	if quiet {
+		progress = io.Discard
+	}
+
+	initialized, err := r.fileUtils.FileExists(filepath.Join(r.DataDir(env), "terraform.tfstate"))
+	if err != nil {
+		return fmt.Errorf("failed to check terraform data directory: %w", err)
+	}
+
+	if initialized {
+		// バックエンド未設定の環境は初期化済みであればよい
+		if env.Backend.Bucket == "" {
+			return nil
+		}
+
+		current, err := r.GetBackendConfig(env)
+		if err == nil && BackendMatches(current, &env.Backend) {
+			return nil
+		}
+
+		if !reinit {
+			if err != nil {
+				return fmt.Errorf("failed to read the initialized backend of environment '%s' (%v); pass --reinit to re-initialize",
+					env.Name, err)
+			}
+			return fmt.Errorf("environment '%s' is initialized with backend s3://%s/%s (%s), but is configured to use s3://%s/%s (%s); pass --reinit to re-initialize",
+				env.Name, current.Bucket, current.Key, current.Region,
+				env.Backend.Bucket, env.Backend.Key, env.Backend.Region)
+		}
+		fmt.Fprintf(progress, "Re-initializing Terraform backend for environment '%s'...\n", env.Name)
+	} else {
+		fmt.Fprintf(progress, "Initializing Terraform for environment '%s'...\n", env.Name)
+	}
+
+	return r.initEnvironment(ctx, env, quiet)
+}
+
+func (r *runner) initEnvironment(ctx context.Context, env *config.Environment, quiet bool) error {
+	initOpts := &InitOptions{
+		Environment: env,
+		Reconfigure: true,
+		Quiet:       quiet,
+	}
+	if env.Backend.Bucket != "" {
+		initOpts.BackendConfigs = BackendConfigs(&env.Backend)
+	}
+
+	result, err := r.Init(ctx, initOpts)
+	if err != nil {
+		return fmt.Errorf("terraform init failed: %w", err)
+	}
+	if !result.Success {
+		return fmt.Errorf("terraform init failed: %s", result.ErrorOutput)
+	}
+	return nil
+}
+
+// BackendConfigs returns the -backend-config values for the backend
+func BackendConfigs(backend *config.BackendConfig) map[string]string {
+	return map[string]string{
+		"bucket": backend.Bucket,
+		"key":    backend.Key,
+		"region": backend.Region,
+	}
+}
+
+// BackendMatches reports whether the initialized backend is the expected one
+func BackendMatches(current, expected *config.BackendConfig) bool {
+	return current.Bucket == expected.Bucket &&
+		current.Key == expected.Key &&
+		current.Region == expected.Region
+}
@@ -0,0 +1,110 @@
+package terraform
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"regexp"
+
+	goversion "github.com/hashicorp/go-version"
+
+	"tfvarenv/config"
+)
+
+const DefaultBinary = "terraform"
+
+// versionOutput represents the output of "terraform -version -json".
+// OpenTofu reports its version under the same key.
+type versionOutput struct {
+	TerraformVersion string `json:"terraform_version"`
+	TofuVersion      string `json:"tofu_version"`
+}
+
+// versionPattern matches the first line of "terraform -version" for versions without -json support
+var versionPattern = regexp.MustCompile(`(?:Terraform|OpenTofu) v(\S+)`)
+
+// ResolveBinary returns the terraform executable of the environment and checks its
+// version against the required version constraint
+func (r *runner) ResolveBinary(ctx context.Context, env *config.Environment) (*BinaryInfo, error) {
+	settings := r.binarySettings(env)
+
+	ver, err := r.binaryVersion(ctx, settings.Binary)
+	if err != nil {
+		return nil, err
+	}
+
+	if settings.RequiredVersion != "" {
+		constraints, err := goversion.NewConstraint(settings.RequiredVersion)
+		if err != nil {
+			return nil, fmt.Errorf("invalid required terraform version %q: %w", settings.RequiredVersion, err)
+		}
+		current, err := goversion.NewVersion(ver)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse version %q of %s: %w", ver, settings.Binary, err)
+		}
+		if !constraints.Check(current) {
+			return nil, fmt.Errorf("%s version %s does not satisfy the required version %s",
+				settings.Binary, ver, settings.RequiredVersion)
+		}
+	}
+
+	return &BinaryInfo{
+		Binary:  settings.Binary,
+		Version: ver,
+	}, nil
+}
+
+// binarySettings merges the environment settings over the project-wide settings
+func (r *runner) binarySettings(env *config.Environment) config.TerraformConfig {
+	settings := *r.defaults
+	if env != nil && env.Terraform != nil {
+		if env.Terraform.Binary != "" {
+			settings.Binary = env.Terraform.Binary
+		}
+		if env.Terraform.RequiredVersion != "" {
+			settings.RequiredVersion = env.Terraform.RequiredVersion
+		}
+	}
+	if settings.Binary == "" {
+		settings.Binary = DefaultBinary
+	}
+	return settings
+}
+
+func (r *runner) binaryVersion(ctx context.Context, binary string) (string, error) {
+	if ver, ok := r.versions[binary]; ok {
+		return ver, nil
+	}
+
+	path, err := exec.LookPath(binary)
+	if err != nil {
+		return "", fmt.Errorf("terraform binary not found: %s", binary)
+	}
+
+	var ver string
+	if out, err := exec.CommandContext(ctx, path, "-version", "-json").Output(); err == nil {
+		var parsed versionOutput
+		if json.Unmarshal(out, &parsed) == nil {
+			ver = parsed.TerraformVersion
+			if ver == "" {
+				ver = parsed.TofuVersion
+			}
+		}
+	}
+	if ver == "" {
+		// -json に対応していない古いバージョン
+		out, err := exec.CommandContext(ctx, path, "-version").Output()
+		if err != nil {
+			return "", fmt.Errorf("failed to get version of %s: %w", binary, err)
+		}
+		match := versionPattern.FindSubmatch(out)
+		if match == nil {
+			return "", fmt.Errorf("failed to parse version of %s", binary)
+		}
+		ver = string(match[1])
+	}
+
+	r.versions[binary] = ver
+	return ver, nil
+}
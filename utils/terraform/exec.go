@@ -0,0 +1,139 @@
+package terraform
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// varFileCommands are the subcommands that accept -var-file
+var varFileCommands = map[string]bool{
+	"plan":    true,
+	"apply":   true,
+	"destroy": true,
+	"import":  true,
+	"refresh": true,
+	"console": true,
+	"test":    true,
+}
+
+// mutatingCommands are the subcommands that change the state
+var mutatingCommands = map[string]bool{
+	"apply":                  true,
+	"destroy":                true,
+	"import":                 true,
+	"refresh":                true,
+	"taint":                  true,
+	"untaint":                true,
+	"force-unlock":           true,
+	"state rm":               true,
+	"state mv":               true,
+	"state push":             true,
+	"state replace-provider": true,
+}
+
+// planFileOptions are the apply options that are still valid when applying a saved plan
+var planFileOptions = map[string]bool{
+	"-lock":             true,
+	"-lock-timeout":     true,
+	"-parallelism":      true,
+	"-input":            true,
+	"-compact-warnings": true,
+	"-json":             true,
+	"-no-color":         true,
+	"-state":            true,
+	"-state-out":        true,
+	"-backup":           true,
+}
+
+// PlanFileOptions filters the options down to those accepted by 'terraform apply <planfile>'.
+// Planning options such as -var-file or -target are already part of the saved plan.
+func PlanFileOptions(options []string) []string {
+	var result []string
+	keep := false
+	for _, option := range options {
+		if !strings.HasPrefix(option, "-") {
+			// The value of the preceding option
+			if keep {
+				result = append(result, option)
+			}
+			continue
+		}
+		name, _, _ := strings.Cut(option, "=")
+		keep = planFileOptions[name]
+		if keep {
+			result = append(result, option)
+		}
+	}
+	return result
+}
+
+// Subcommand returns the subcommand of the arguments, e.g. "import" or "state rm"
+func Subcommand(args []string) string {
+	var words []string
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "-") {
+			continue
+		}
+		words = append(words, arg)
+		if len(words) == 2 || words[0] != "state" {
+			break
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// IsMutating reports whether the subcommand changes the state
+func IsMutating(subcommand string) bool {
+	return mutatingCommands[subcommand]
+}
+
+// Exec runs an arbitrary terraform command in the context of the environment
+func (r *runner) Exec(ctx context.Context, opts *ExecOptions) (*ExecutionResult, error) {
+	// AWS account verification
+	accountID, err := r.awsClient.GetAccountID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get AWS account ID: %w", err)
+	}
+	if accountID != opts.Environment.AWS.AccountID {
+		return nil, fmt.Errorf("current AWS account (%s) does not match environment configuration (%s)",
+			accountID, opts.Environment.AWS.AccountID)
+	}
+
+	if err := r.EnsureBackend(ctx, opts.Environment, opts.Reinit); err != nil {
+		return nil, err
+	}
+
+	args := append([]string(nil), opts.Args...)
+	if opts.VarFile != "" && varFileCommands[Subcommand(args)] && !hasVarFile(args) {
+		varFile, err := filepath.Abs(opts.VarFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve tfvars path: %w", err)
+		}
+		args = insertAfterSubcommand(args, "-var-file="+varFile)
+	}
+
+	return r.runCommand(ctx, opts.Environment, args)
+}
+
+func hasVarFile(args []string) bool {
+	for _, arg := range args {
+		if arg == "-var-file" || strings.HasPrefix(arg, "-var-file=") {
+			return true
+		}
+	}
+	return false
+}
+
+// insertAfterSubcommand inserts an option right after the subcommand, before any positional arguments
+func insertAfterSubcommand(args []string, option string) []string {
+	for i, arg := range args {
+		if !strings.HasPrefix(arg, "-") {
+			result := append([]string(nil), args[:i+1]...)
+			result = append(result, option)
+			return append(result, args[i+1:]...)
+		}
+	}
+	return append(args, option)
+}
@@ -0,0 +1,22 @@
+package terraform
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPlanFileOptions(t *testing.T) {
+	options := []string{
+		"-lock-timeout=60s",
+		"-var-file=extra.tfvars",
+		"-target", "aws_s3_bucket.logs",
+		"-parallelism", "5",
+		"-refresh=false",
+		"-no-color",
+	}
+	got := PlanFileOptions(options)
+	want := []string{"-lock-timeout=60s", "-parallelism", "5", "-no-color"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("PlanFileOptions() = %v, want %v", got, want)
+	}
+}
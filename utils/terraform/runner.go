@@ -11,8 +11,11 @@ import (
 	"strings"
 	"time"
 
+	"tfvarenv/config"
 	"tfvarenv/utils/aws"
+	"tfvarenv/utils/envvars"
 	"tfvarenv/utils/file"
+	"tfvarenv/utils/interrupt"
 )
 
 type Runner interface {
@@ -21,19 +24,43 @@ type Runner interface {
 	Apply(ctx context.Context, opts *ApplyOptions) (*ExecutionResult, error)
 	Destroy(ctx context.Context, opts *DestroyOptions) (*ExecutionResult, error)
 	Validate(ctx context.Context) (*ValidationResult, error)
+	Exec(ctx context.Context, opts *ExecOptions) (*ExecutionResult, error)
+	ShowPlanJSON(ctx context.Context, env *config.Environment, planFile string) ([]byte, error)
+	GetBackendConfig(env *config.Environment) (*config.BackendConfig, error)
+	DataDir(env *config.Environment) string
+	EnsureBackend(ctx context.Context, env *config.Environment, reinit bool) error
+	ResolveBinary(ctx context.Context, env *config.Environment) (*BinaryInfo, error)
+	EnvVars(ctx context.Context, env *config.Environment) (map[string]string, error)
 }
 
+// GracefulStopTimeout is how long terraform may take to stop after an interrupt before it is killed
+const GracefulStopTimeout = 5 * time.Minute
+
+// DataDirRoot is the directory that holds the terraform data directory of each environment
+const DataDirRoot = ".tfvarenv/data"
+
 type runner struct {
 	awsClient aws.Client
 	fileUtils file.Utils
 	workDir   string
+	defaults  *config.TerraformConfig
+	versions  map[string]string
+	envVars   envvars.Manager
+	resolved  map[string]map[string]string
 }
 
-func NewRunner(awsClient aws.Client, fileUtils file.Utils) Runner {
+func NewRunner(awsClient aws.Client, fileUtils file.Utils, defaults *config.TerraformConfig) Runner {
+	if defaults == nil {
+		defaults = &config.TerraformConfig{}
+	}
 	return &runner{
 		awsClient: awsClient,
 		fileUtils: fileUtils,
 		workDir:   ".",
+		defaults:  defaults,
+		versions:  make(map[string]string),
+		envVars:   envvars.NewManager(awsClient),
+		resolved:  make(map[string]map[string]string),
 	}
 }
 
@@ -56,7 +83,7 @@ func (r *runner) Init(ctx context.Context, opts *InitOptions) (*ExecutionResult,
 		args = append(args, opts.Options...)
 	}
 
-	return r.runCommand(ctx, args)
+	return r.execute(ctx, opts.Environment, args, !opts.Quiet)
 }
 
 func (r *runner) Plan(ctx context.Context, opts *PlanOptions) (*ExecutionResult, error) {
@@ -70,6 +97,10 @@ func (r *runner) Plan(ctx context.Context, opts *PlanOptions) (*ExecutionResult,
 			accountID, opts.Environment.AWS.AccountID)
 	}
 
+	if err := r.ensureBackend(ctx, opts.Environment, opts.Reinit, opts.Quiet); err != nil {
+		return nil, err
+	}
+
 	args := []string{"plan"}
 
 	// Handle remote vs local tfvars
@@ -99,7 +130,21 @@ func (r *runner) Plan(ctx context.Context, opts *PlanOptions) (*ExecutionResult,
 	}
 
 	if opts.VarFile != "" {
-		args = append(args, "-var-file="+opts.VarFile)
+		varFile, err := filepath.Abs(opts.VarFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve tfvars path: %w", err)
+		}
+		args = append(args, "-var-file="+varFile)
+	}
+	if opts.Destroy {
+		args = append(args, "-destroy")
+	}
+	if opts.Out != "" {
+		out, err := filepath.Abs(opts.Out)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve plan file path: %w", err)
+		}
+		args = append(args, "-out="+out)
 	}
 	if opts.NoColor {
 		args = append(args, "-no-color")
@@ -108,7 +153,7 @@ func (r *runner) Plan(ctx context.Context, opts *PlanOptions) (*ExecutionResult,
 		args = append(args, opts.Options...)
 	}
 
-	return r.runCommand(ctx, args)
+	return r.execute(ctx, opts.Environment, args, !opts.Quiet)
 }
 
 func (r *runner) Apply(ctx context.Context, opts *ApplyOptions) (*ExecutionResult, error) {
@@ -122,8 +167,26 @@ func (r *runner) Apply(ctx context.Context, opts *ApplyOptions) (*ExecutionResul
 			accountID, opts.Environment.AWS.AccountID)
 	}
 
+	if err := r.EnsureBackend(ctx, opts.Environment, opts.Reinit); err != nil {
+		return nil, err
+	}
+
 	args := []string{"apply"}
 
+	// A saved plan already contains the variables and needs no approval
+	if opts.PlanFile != "" {
+		planFile, err := filepath.Abs(opts.PlanFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve plan file path: %w", err)
+		}
+		if opts.NoColor {
+			args = append(args, "-no-color")
+		}
+		args = append(args, opts.Options...)
+		args = append(args, planFile)
+		return r.runCommand(ctx, opts.Environment, args)
+	}
+
 	// Handle remote vs local tfvars
 	if opts.Remote {
 		tmpDir := filepath.Join(".tmp", opts.Environment.Name)
@@ -154,7 +217,11 @@ func (r *runner) Apply(ctx context.Context, opts *ApplyOptions) (*ExecutionResul
 	}
 
 	if opts.VarFile != "" {
-		args = append(args, "-var-file="+opts.VarFile)
+		varFile, err := filepath.Abs(opts.VarFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve tfvars path: %w", err)
+		}
+		args = append(args, "-var-file="+varFile)
 	}
 	if opts.AutoApprove {
 		args = append(args, "-auto-approve")
@@ -166,12 +233,12 @@ func (r *runner) Apply(ctx context.Context, opts *ApplyOptions) (*ExecutionResul
 		args = append(args, opts.Options...)
 	}
 
-	return r.runCommand(ctx, args)
+	return r.runCommand(ctx, opts.Environment, args)
 }
 
 func (r *runner) Validate(ctx context.Context) (*ValidationResult, error) {
 	args := []string{"validate", "-json"}
-	result, err := r.runCommand(ctx, args)
+	result, err := r.runCommand(ctx, nil, args)
 	if err != nil {
 		return nil, err
 	}
@@ -186,10 +253,18 @@ func (r *runner) Validate(ctx context.Context) (*ValidationResult, error) {
 }
 
 func (r *runner) Destroy(ctx context.Context, opts *DestroyOptions) (*ExecutionResult, error) {
+	if err := r.EnsureBackend(ctx, opts.Environment, opts.Reinit); err != nil {
+		return nil, err
+	}
+
 	args := []string{"destroy"}
 
 	if opts.VarFile != "" {
-		args = append(args, "-var-file="+opts.VarFile)
+		varFile, err := filepath.Abs(opts.VarFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve tfvars path: %w", err)
+		}
+		args = append(args, "-var-file="+varFile)
 	}
 	if opts.AutoApprove {
 		args = append(args, "-auto-approve")
@@ -201,20 +276,105 @@ func (r *runner) Destroy(ctx context.Context, opts *DestroyOptions) (*ExecutionR
 		args = append(args, opts.Options...)
 	}
 
-	return r.runCommand(ctx, args)
+	return r.runCommand(ctx, opts.Environment, args)
 }
 
-func (r *runner) runCommand(ctx context.Context, args []string) (*ExecutionResult, error) {
-	cmd := exec.CommandContext(ctx, "terraform", args...)
+// ShowPlanJSON returns the JSON representation of a saved plan
+func (r *runner) ShowPlanJSON(ctx context.Context, env *config.Environment, planFile string) ([]byte, error) {
+	path, err := filepath.Abs(planFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve plan file path: %w", err)
+	}
+
+	result, err := r.execute(ctx, env, []string{"show", "-json", path}, false)
+	if err != nil {
+		if result != nil && result.ErrorOutput != "" {
+			return nil, fmt.Errorf("terraform show failed: %s", strings.TrimSpace(result.ErrorOutput))
+		}
+		return nil, err
+	}
+	return []byte(result.Output), nil
+}
+
+// DataDir returns the terraform data directory of the environment
+func (r *runner) DataDir(env *config.Environment) string {
+	return filepath.Join(DataDirRoot, env.Name)
+}
+
+// EnvVars returns the environment variables terraform runs with for the environment:
+// the resolved env_vars of the environment and TF_DATA_DIR
+func (r *runner) EnvVars(ctx context.Context, env *config.Environment) (map[string]string, error) {
+	resolved, ok := r.resolved[env.Name]
+	if !ok {
+		var err error
+		resolved, err = r.envVars.Resolve(ctx, env)
+		if err != nil {
+			return nil, err
+		}
+		r.resolved[env.Name] = resolved
+	}
+
+	dataDir, err := filepath.Abs(r.DataDir(env))
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve data directory: %w", err)
+	}
+
+	vars := make(map[string]string, len(resolved)+1)
+	for name, value := range resolved {
+		vars[name] = value
+	}
+	vars["TF_DATA_DIR"] = dataDir
+	return vars, nil
+}
+
+// runCommand runs terraform. With an environment, terraform runs in the environment's
+// working directory with the environment's variables, and TF_DATA_DIR points to the
+// environment's data directory.
+func (r *runner) runCommand(ctx context.Context, env *config.Environment, args []string) (*ExecutionResult, error) {
+	return r.execute(ctx, env, args, true)
+}
+
+// execute runs terraform, streaming its output to the terminal when stream is set
+func (r *runner) execute(ctx context.Context, env *config.Environment, args []string, stream bool) (*ExecutionResult, error) {
+	binary := r.binarySettings(env).Binary
+	if env != nil {
+		info, err := r.ResolveBinary(ctx, env)
+		if err != nil {
+			return nil, err
+		}
+		binary = info.Binary
+	}
+
+	cmd := exec.CommandContext(ctx, binary, args...)
+	// 中断時は terraform に終了処理をさせる（state のロック解除や書き込み）
+	cmd.Cancel = func() error {
+		return interrupt.Forward(ctx, cmd.Process)
+	}
+	cmd.WaitDelay = GracefulStopTimeout
 	cmd.Dir = r.workDir
+	if env != nil {
+		// 環境ごとのルートモジュールで実行する（-var-file と TF_DATA_DIR は絶対パス）
+		cmd.Dir = filepath.Join(r.workDir, env.GetWorkingDir())
+	}
 
 	cmd.Stdin = os.Stdin
 
 	var stdoutBuf, stderrBuf bytes.Buffer
-	cmd.Stdout = io.MultiWriter(os.Stdout, &stdoutBuf)
-	cmd.Stderr = io.MultiWriter(os.Stderr, &stderrBuf)
+	cmd.Stdout = &stdoutBuf
+	cmd.Stderr = &stderrBuf
+	if stream {
+		cmd.Stdout = io.MultiWriter(os.Stdout, &stdoutBuf)
+		cmd.Stderr = io.MultiWriter(os.Stderr, &stderrBuf)
+	}
 
 	cmd.Env = os.Environ()
+	if env != nil {
+		vars, err := r.EnvVars(ctx, env)
+		if err != nil {
+			return nil, err
+		}
+		cmd.Env = envvars.Environ(cmd.Env, vars)
+	}
 
 	startTime := time.Now()
 	err := cmd.Run()
@@ -226,7 +386,7 @@ func (r *runner) runCommand(ctx context.Context, args []string) (*ExecutionResul
 		Output:      stdoutBuf.String(),
 		ErrorOutput: stderrBuf.String(),
 		Duration:    duration,
-		CommandLine: fmt.Sprintf("terraform %s", strings.Join(args, " ")),
+		CommandLine: fmt.Sprintf("%s %s", binary, strings.Join(args, " ")),
 	}
 
 	if err != nil {
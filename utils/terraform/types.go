@@ -6,12 +6,15 @@ import (
 
 // InitOptions represents options for terraform init
 type InitOptions struct {
+	Environment    *config.Environment
 	BackendConfig  string
 	BackendConfigs map[string]string
 	Reconfigure    bool
 	ForceCopy      bool
 	NoColor        bool
 	Options        []string
+	// Quiet captures the output without streaming it to the terminal
+	Quiet bool
 }
 
 // PlanOptions represents options for terraform plan
@@ -22,6 +25,14 @@ type PlanOptions struct {
 	VarFile     string
 	NoColor     bool
 	Options     []string
+	// Out saves the plan to a file
+	Out string
+	// Destroy plans the destruction of all resources
+	Destroy bool
+	// Reinit re-initializes the backend when it does not match the environment
+	Reinit bool
+	// Quiet captures the output without streaming it to the terminal
+	Quiet bool
 }
 
 // ApplyOptions represents options for terraform apply
@@ -33,6 +44,19 @@ type ApplyOptions struct {
 	AutoApprove bool
 	NoColor     bool
 	Options     []string
+	// PlanFile applies a saved plan instead of the tfvars file
+	PlanFile string
+	// Reinit re-initializes the backend when it does not match the environment
+	Reinit bool
+}
+
+// ExecOptions represents options for running an arbitrary terraform command
+type ExecOptions struct {
+	Environment *config.Environment
+	Args        []string
+	// VarFile is added to subcommands that accept -var-file
+	VarFile string
+	Reinit  bool
 }
 
 // ExecutionResult represents the result of a terraform command execution
@@ -45,6 +69,12 @@ type ExecutionResult struct {
 	CommandLine string
 }
 
+// BinaryInfo represents the terraform executable used for an environment
+type BinaryInfo struct {
+	Binary  string
+	Version string
+}
+
 // ValidationResult represents the result of terraform configuration validation
 type ValidationResult struct {
 	Valid    bool
@@ -65,4 +95,6 @@ type DestroyOptions struct {
 	AutoApprove bool
 	NoColor     bool
 	Options     []string
+	// Reinit re-initializes the backend when it does not match the environment
+	Reinit bool
 }
@@ -0,0 +1,42 @@
+package tfvars
+
+// Diff returns the semantic changes between two tfvars files. Either file may be nil.
+func Diff(oldFile, newFile *File) []Change {
+	oldVars := variableMap(oldFile)
+	newVars := variableMap(newFile)
+
+	var changes []Change
+	for _, v := range variables(newFile) {
+		old, exists := oldVars[v.Name]
+		switch {
+		case !exists:
+			changes = append(changes, Change{Name: v.Name, Type: ChangeAdded, NewValue: v.Value})
+		case !old.Equal(&v):
+			changes = append(changes, Change{Name: v.Name, Type: ChangeModified, OldValue: old.Value, NewValue: v.Value})
+		}
+	}
+
+	for _, v := range variables(oldFile) {
+		if _, exists := newVars[v.Name]; !exists {
+			changes = append(changes, Change{Name: v.Name, Type: ChangeRemoved, OldValue: v.Value})
+		}
+	}
+
+	return changes
+}
+
+func variables(f *File) []Variable {
+	if f == nil {
+		return nil
+	}
+	return f.Variables()
+}
+
+func variableMap(f *File) map[string]*Variable {
+	vars := variables(f)
+	m := make(map[string]*Variable, len(vars))
+	for i := range vars {
+		m[vars[i].Name] = &vars[i]
+	}
+	return m
+}
@@ -0,0 +1,153 @@
+package tfvars
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	markerLocal  = "<<<<<<<"
+	markerSep    = "======="
+	markerRemote = ">>>>>>>"
+)
+
+// Merge performs a variable-level three-way merge. The result keeps the layout of
+// the local file and takes over every change made only on the remote side.
+// Base may be nil when there is no common ancestor.
+func Merge(base, local, remote *File) (*MergeResult, error) {
+	merged, err := Parse(local.Bytes(), local.name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy local file: %w", err)
+	}
+
+	baseVars := variableMap(base)
+	localVars := variableMap(local)
+	remoteVars := variableMap(remote)
+
+	result := &MergeResult{File: merged}
+	for _, name := range mergeOrder(base, local, remote) {
+		b, l, r := baseVars[name], localVars[name], remoteVars[name]
+
+		switch {
+		case l.Equal(r), b.Equal(r):
+			// Both sides agree, or only the local side changed
+			continue
+		case b.Equal(l):
+			// Only the remote side changed
+			if r == nil {
+				merged.Unset(name)
+			} else if err := merged.Set(name, r.Value); err != nil {
+				return nil, fmt.Errorf("failed to apply remote value of %s: %w", name, err)
+			}
+		default:
+			result.Conflicts = append(result.Conflicts, Conflict{
+				Name:   name,
+				Base:   b,
+				Local:  l,
+				Remote: r,
+			})
+		}
+	}
+
+	return result, nil
+}
+
+func mergeOrder(base, local, remote *File) []string {
+	seen := make(map[string]bool)
+	var names []string
+	// ローカル、リモート、ベースの順に出現順で並べる
+	for _, f := range []*File{local, remote, base} {
+		for _, v := range variables(f) {
+			if !seen[v.Name] {
+				seen[v.Name] = true
+				names = append(names, v.Name)
+			}
+		}
+	}
+	return names
+}
+
+// Resolve resolves a conflict with the given value. A nil value removes the variable.
+func (r *MergeResult) Resolve(name string, value *Variable) error {
+	index := -1
+	for i, c := range r.Conflicts {
+		if c.Name == name {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return fmt.Errorf("no conflict for variable: %s", name)
+	}
+
+	if value == nil {
+		r.File.Unset(name)
+	} else if err := r.File.Set(name, value.Value); err != nil {
+		return err
+	}
+
+	r.Conflicts = append(r.Conflicts[:index], r.Conflicts[index+1:]...)
+	return nil
+}
+
+// ConflictMarkers returns the merged content with conflict markers around every unresolved conflict
+func (r *MergeResult) ConflictMarkers(localLabel, remoteLabel string) []byte {
+	type section struct {
+		start, end int
+		text       string
+	}
+
+	content := r.File.Bytes()
+	var sections []section
+	var appended []string
+	for _, c := range r.Conflicts {
+		var b strings.Builder
+		fmt.Fprintf(&b, "%s %s\n", markerLocal, localLabel)
+		start, end, ok := r.File.lineRange(c.Name)
+		if ok {
+			b.Write(content[start:end])
+			if !bytes.HasSuffix(content[start:end], []byte("\n")) {
+				b.WriteString("\n")
+			}
+		}
+		fmt.Fprintf(&b, "%s\n", markerSep)
+		if c.Remote != nil {
+			fmt.Fprintf(&b, "%s = %s\n", c.Name, c.Remote.Value)
+		}
+		fmt.Fprintf(&b, "%s %s\n", markerRemote, remoteLabel)
+
+		if ok {
+			sections = append(sections, section{start: start, end: end, text: b.String()})
+		} else {
+			appended = append(appended, b.String())
+		}
+	}
+
+	// 後ろから置換してオフセットを維持する
+	sort.Slice(sections, func(i, j int) bool {
+		return sections[i].start > sections[j].start
+	})
+	for _, s := range sections {
+		content = splice(content, s.start, s.end, []byte(s.text))
+	}
+	for _, text := range appended {
+		if len(content) > 0 && !bytes.HasSuffix(content, []byte("\n")) {
+			content = append(content, '\n')
+		}
+		content = append(content, []byte(text)...)
+	}
+
+	return content
+}
+
+// HasConflictMarkers reports whether the content still contains conflict markers
+func HasConflictMarkers(content []byte) bool {
+	for _, line := range strings.Split(string(content), "\n") {
+		if strings.HasPrefix(line, markerLocal) || strings.HasPrefix(line, markerRemote) {
+			return true
+		}
+	}
+	return false
+}
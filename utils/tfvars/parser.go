@@ -0,0 +1,210 @@
+package tfvars
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+)
+
+// File is a parsed tfvars file. Edits are applied to the raw content so that
+// comments, ordering and formatting of untouched lines are preserved.
+type File struct {
+	name    string
+	content []byte
+}
+
+// Parse parses the content of a tfvars file
+func Parse(content []byte, filename string) (*File, error) {
+	if err := Validate(content, filename); err != nil {
+		return nil, err
+	}
+
+	return &File{
+		name:    filename,
+		content: append([]byte(nil), content...),
+	}, nil
+}
+
+// Validate checks that the content is a syntactically valid tfvars file
+func Validate(content []byte, filename string) error {
+	_, err := parseBody(content, filename)
+	return err
+}
+
+// Bytes returns the current content of the file
+func (f *File) Bytes() []byte {
+	return append([]byte(nil), f.content...)
+}
+
+// Variables returns all variables in the order they appear in the file
+func (f *File) Variables() []Variable {
+	attrs, err := f.attributes()
+	if err != nil {
+		return nil
+	}
+
+	vars := make([]Variable, 0, len(attrs))
+	for _, attr := range attrs {
+		rng := attr.Expr.Range()
+		vars = append(vars, newVariable(attr.Name, f.content[rng.Start.Byte:rng.End.Byte], attr.Expr))
+	}
+	return vars
+}
+
+// Get returns the variable with the given name
+func (f *File) Get(name string) (*Variable, bool) {
+	for _, v := range f.Variables() {
+		if v.Name == name {
+			return &v, true
+		}
+	}
+	return nil, false
+}
+
+// Set sets the variable to the given HCL expression, keeping its position if it already exists
+func (f *File) Set(name, expr string) error {
+	if !hclsyntax.ValidIdentifier(name) {
+		return fmt.Errorf("invalid variable name: %s", name)
+	}
+
+	expr = strings.TrimSpace(expr)
+	if _, diags := hclsyntax.ParseExpression([]byte(expr), "value", hcl.InitialPos); diags.HasErrors() {
+		return fmt.Errorf("invalid value %q: %s", expr, diags.Error())
+	}
+
+	attr, err := f.attribute(name)
+	if err != nil {
+		return err
+	}
+
+	var updated []byte
+	if attr != nil {
+		rng := attr.Expr.Range()
+		updated = splice(f.content, rng.Start.Byte, rng.End.Byte, []byte(expr))
+	} else {
+		updated = f.Bytes()
+		if len(updated) > 0 && !bytes.HasSuffix(updated, []byte("\n")) {
+			updated = append(updated, '\n')
+		}
+		updated = append(updated, []byte(fmt.Sprintf("%s = %s\n", name, expr))...)
+	}
+
+	if err := Validate(updated, f.name); err != nil {
+		return fmt.Errorf("failed to set %s: %w", name, err)
+	}
+	f.content = updated
+	return nil
+}
+
+// Unset removes the variable from the file and reports whether it existed
+func (f *File) Unset(name string) bool {
+	start, end, ok := f.lineRange(name)
+	if !ok {
+		return false
+	}
+
+	f.content = splice(f.content, start, end, nil)
+	return true
+}
+
+// lineRange returns the byte range of the whole lines of an attribute, including a trailing comment
+func (f *File) lineRange(name string) (int, int, bool) {
+	attr, err := f.attribute(name)
+	if err != nil || attr == nil {
+		return 0, 0, false
+	}
+
+	start := attr.SrcRange.Start.Byte
+	for start > 0 && (f.content[start-1] == ' ' || f.content[start-1] == '\t') {
+		start--
+	}
+	end := attr.SrcRange.End.Byte
+	if i := bytes.IndexByte(f.content[end:], '\n'); i >= 0 {
+		end += i + 1
+	} else {
+		end = len(f.content)
+	}
+
+	return start, end, true
+}
+
+func (f *File) attributes() ([]*hclsyntax.Attribute, error) {
+	body, err := parseBody(f.content, f.name)
+	if err != nil {
+		return nil, err
+	}
+
+	attrs := make([]*hclsyntax.Attribute, 0, len(body.Attributes))
+	for _, attr := range body.Attributes {
+		attrs = append(attrs, attr)
+	}
+	sort.Slice(attrs, func(i, j int) bool {
+		return attrs[i].SrcRange.Start.Byte < attrs[j].SrcRange.Start.Byte
+	})
+	return attrs, nil
+}
+
+func (f *File) attribute(name string) (*hclsyntax.Attribute, error) {
+	body, err := parseBody(f.content, f.name)
+	if err != nil {
+		return nil, err
+	}
+	return body.Attributes[name], nil
+}
+
+func splice(content []byte, start, end int, replacement []byte) []byte {
+	result := make([]byte, 0, len(content)-(end-start)+len(replacement))
+	result = append(result, content[:start]...)
+	result = append(result, replacement...)
+	return append(result, content[end:]...)
+}
+
+// Equal reports whether both variables hold the same value regardless of formatting
+func (v *Variable) Equal(other *Variable) bool {
+	if v == nil || other == nil {
+		return v == nil && other == nil
+	}
+	return v.canonical == other.canonical
+}
+
+func parseBody(content []byte, filename string) (*hclsyntax.Body, error) {
+	file, diags := hclsyntax.ParseConfig(content, filename, hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("invalid tfvars syntax: %s", diags.Error())
+	}
+
+	body, ok := file.Body.(*hclsyntax.Body)
+	if !ok {
+		return nil, fmt.Errorf("invalid tfvars syntax: unexpected body type")
+	}
+	if len(body.Blocks) > 0 {
+		block := body.Blocks[0]
+		return nil, fmt.Errorf("invalid tfvars syntax: %s: blocks are not allowed in tfvars files",
+			block.DefRange().String())
+	}
+
+	return body, nil
+}
+
+func newVariable(name string, src []byte, expr hclsyntax.Expression) Variable {
+	v := Variable{
+		Name:  name,
+		Value: strings.TrimSpace(string(src)),
+	}
+
+	// 評価可能な値はJSONに正規化して比較する
+	if val, diags := expr.Value(nil); !diags.HasErrors() && val.IsWhollyKnown() {
+		if data, err := (ctyjson.SimpleJSONValue{Value: val}).MarshalJSON(); err == nil {
+			v.canonical = string(data)
+			return v
+		}
+	}
+	v.canonical = strings.Join(strings.Fields(string(hclwrite.Format(src))), " ")
+	return v
+}
@@ -0,0 +1,36 @@
+package tfvars
+
+const (
+	ChangeAdded    = "added"
+	ChangeRemoved  = "removed"
+	ChangeModified = "modified"
+)
+
+// Variable represents a single variable assignment in a tfvars file
+type Variable struct {
+	Name      string
+	Value     string // Expression source as written in the file
+	canonical string
+}
+
+// Change represents a semantic change of a single variable
+type Change struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	OldValue string `json:"old_value,omitempty"`
+	NewValue string `json:"new_value,omitempty"`
+}
+
+// Conflict represents a variable changed differently on both sides of a merge
+type Conflict struct {
+	Name   string
+	Base   *Variable
+	Local  *Variable
+	Remote *Variable
+}
+
+// MergeResult represents the result of a three-way merge
+type MergeResult struct {
+	File      *File
+	Conflicts []Conflict
+}
@@ -0,0 +1,41 @@
+package tfvars
+
+import (
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// ParseValue converts user input into an HCL expression.
+// Numbers, booleans, quoted strings, lists and maps are accepted in HCL or JSON syntax.
+// Anything else, or any input when asString is set, is treated as a plain string.
+func ParseValue(input string, asString bool) string {
+	trimmed := strings.TrimSpace(input)
+	if !asString && trimmed != "" {
+		expr, diags := hclsyntax.ParseExpression([]byte(trimmed), "value", hcl.InitialPos)
+		if !diags.HasErrors() {
+			if _, diags := expr.Value(nil); !diags.HasErrors() {
+				return trimmed
+			}
+		}
+	}
+
+	return strings.TrimSpace(string(hclwrite.TokensForValue(cty.StringVal(input)).Bytes()))
+}
+
+// StringValue returns the value of a string variable without quotes
+func (v *Variable) StringValue() (string, bool) {
+	expr, diags := hclsyntax.ParseExpression([]byte(v.Value), v.Name, hcl.InitialPos)
+	if diags.HasErrors() {
+		return "", false
+	}
+
+	val, diags := expr.Value(nil)
+	if diags.HasErrors() || val.IsNull() || !val.IsKnown() || val.Type() != cty.String {
+		return "", false
+	}
+	return val.AsString(), true
+}
@@ -21,6 +21,8 @@ type Manager interface {
 	GetVersions(ctx context.Context, opts *QueryOptions) ([]Version, error)
 	GetLatestVersion(ctx context.Context) (*Version, error)
 	GetVersion(ctx context.Context, versionID string) (*Version, error)
+	GetVersionContent(ctx context.Context, versionID string) ([]byte, error)
+	GetVariableHistory(ctx context.Context) (*VariableHistory, error)
 	GetStats(ctx context.Context) (*VersionStats, error)
 	CompareVersions(ctx context.Context, v1, v2 string) ([]string, error)
 }
@@ -134,6 +136,10 @@ func (m *manager) GetVersion(ctx context.Context, versionID string) (*Version, e
 	return nil, fmt.Errorf("version not found: %s", versionID)
 }
 
+func (m *manager) GetVersionContent(ctx context.Context, versionID string) ([]byte, error) {
+	return m.downloadVersion(ctx, versionID)
+}
+
 func (m *manager) GetStats(ctx context.Context) (*VersionStats, error) {
 	management, err := m.getVersionManagement(ctx)
 	if err != nil {
@@ -310,6 +316,14 @@ func (m *manager) downloadVersion(ctx context.Context, versionID string) ([]byte
 	return output.Content, nil
 }
 
+// ShortID returns the abbreviated form of a version ID used in output
+func ShortID(versionID string) string {
+	if len(versionID) > 8 {
+		return versionID[:8]
+	}
+	return versionID
+}
+
 func compareContents(content1, content2 []byte) []string {
 	// Simple line-by-line comparison
 	lines1 := strings.Split(string(content1), "\n")
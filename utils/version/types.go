@@ -13,6 +13,10 @@ type Version struct {
 	UploadedBy  string            `json:"uploaded_by"`
 	Size        int64             `json:"size"`
 	Metadata    map[string]string `json:"metadata,omitempty"`
+	// ParentVersionID is the version this version was derived from
+	ParentVersionID string `json:"parent_version_id,omitempty"`
+	// MergeParentVersionID is the second parent of a version created by merge
+	MergeParentVersionID string `json:"merge_parent_version_id,omitempty"`
 }
 
 // VersionManagement represents the version management file structure
@@ -37,6 +41,25 @@ type QueryOptions struct {
 	SearchText string
 }
 
+// VariableChange represents a version in which a variable got a new value
+type VariableChange struct {
+	Version *Version
+	Value   string
+	Removed bool
+	// VersionIDs lists every version in which the variable kept this value, oldest first
+	VersionIDs []string
+}
+
+// VariableHistory represents the value history of every variable
+type VariableHistory struct {
+	// Changes maps a variable name to its changes, oldest first
+	Changes map[string][]VariableChange
+	// Names lists the variables of the latest version in file order
+	Names []string
+	// SkippedVersions lists versions that could not be downloaded or parsed
+	SkippedVersions []string
+}
+
 // VersionStats represents statistics about versions
 type VersionStats struct {
 	TotalVersions    int
@@ -0,0 +1,99 @@
+package version
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"tfvarenv/utils/tfvars"
+)
+
+// GetVariableHistory walks all versions in the index from oldest to newest
+// and records every change of every variable.
+func (m *manager) GetVariableHistory(ctx context.Context) (*VariableHistory, error) {
+	management, err := m.getVersionManagement(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get version management: %w", err)
+	}
+
+	versions := make([]Version, len(management.Versions))
+	copy(versions, management.Versions)
+	sort.SliceStable(versions, func(i, j int) bool {
+		return versions[i].Timestamp.Before(versions[j].Timestamp)
+	})
+
+	history := &VariableHistory{
+		Changes: make(map[string][]VariableChange),
+	}
+
+	// 同じ内容のバージョンは一度だけパースする
+	parsed := make(map[string]*tfvars.File)
+	var prev, latest *tfvars.File
+	for i := range versions {
+		ver := &versions[i]
+
+		current, ok := parsed[ver.Hash]
+		if !ok || ver.Hash == "" {
+			content, err := m.downloadVersion(ctx, ver.VersionID)
+			if err != nil {
+				history.SkippedVersions = append(history.SkippedVersions, ver.VersionID)
+				continue
+			}
+			current, err = tfvars.Parse(content, "version "+ver.VersionID)
+			if err != nil {
+				history.SkippedVersions = append(history.SkippedVersions, ver.VersionID)
+				continue
+			}
+			if ver.Hash != "" {
+				parsed[ver.Hash] = current
+			}
+		}
+
+		recordChanges(history, ver, prev, current)
+		prev = current
+		latest = current
+	}
+
+	if latest != nil {
+		for _, v := range latest.Variables() {
+			history.Names = append(history.Names, v.Name)
+		}
+	}
+
+	return history, nil
+}
+
+func recordChanges(history *VariableHistory, ver *Version, prev, current *tfvars.File) {
+	prevVars := make(map[string]*tfvars.Variable)
+	if prev != nil {
+		for _, v := range prev.Variables() {
+			v := v
+			prevVars[v.Name] = &v
+		}
+	}
+
+	for _, v := range current.Variables() {
+		v := v
+		changes := history.Changes[v.Name]
+		if old, exists := prevVars[v.Name]; exists && old.Equal(&v) && len(changes) > 0 {
+			last := &changes[len(changes)-1]
+			last.VersionIDs = append(last.VersionIDs, ver.VersionID)
+		} else {
+			history.Changes[v.Name] = append(changes, VariableChange{
+				Version:    ver,
+				Value:      v.Value,
+				VersionIDs: []string{ver.VersionID},
+			})
+		}
+		delete(prevVars, v.Name)
+	}
+
+	// Variables left in prevVars were removed in this version
+	for name := range prevVars {
+		history.Changes[name] = append(history.Changes[name], VariableChange{
+			Version:    ver,
+			Removed:    true,
+			VersionIDs: []string{ver.VersionID},
+		})
+	}
+}